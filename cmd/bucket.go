@@ -0,0 +1,538 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/devusSs/minly/internal/config"
+	"github.com/devusSs/minly/internal/log"
+	"github.com/devusSs/minly/internal/minio"
+	"github.com/devusSs/minly/internal/preview"
+)
+
+var bucketCmd = &cobra.Command{
+	Use:   "bucket",
+	Short: "Lists, inspects and summarizes the objects in the bucket",
+	PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+		err := log.Setup()
+		checkErr(err, "failed to setup log package")
+
+		go func() {
+			err = log.CleanOld()
+			if err != nil {
+				log.Logger().Error().Err(err).Msg("failed to clean old log files")
+			}
+		}()
+
+		var overrides map[string]any
+		overrides, err = flagOverrides(cmd, bucketConfigFlags)
+		logErr(err, "failed to read config flags")
+
+		cfg, err = config.Load(overrides)
+		logErr(err, "failed to load config")
+
+		log.Logger().Info().Any("config", cfg).
+			Msg("config loaded successfully")
+	},
+	PersistentPostRun: func(_ *cobra.Command, _ []string) {
+		err := log.Flush()
+		checkErr(err, "failed to flush log package")
+	},
+}
+
+var bucketLsCmd = &cobra.Command{
+	Use:   "ls [prefix]",
+	Short: "Lists objects with size, modification time and owner",
+	Long: `Lists objects with size, modification time and owner.
+--long adds content type, original file name and metadata, providers other than MinIO
+need a request per object for them.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		want, err := parseLabels("tag", bucketTags)
+		logErr(err, "invalid --tag flag")
+
+		var mc *minio.Client
+		mc, err = newBucketClient(cfg)
+		logErr(err, "failed to setup MinIO client")
+
+		if len(want) > 0 {
+			err = mc.Provider().Require(minio.FeatureTagging)
+			logErr(err, "cannot filter by tags")
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		var objects []minio.ObjectInfo
+		objects, err = mc.ListObjects(ctx, minio.ListOptions{
+			Prefix:    optionalArg(args),
+			Recursive: bucketRecursive,
+			Metadata:  bucketLong,
+			Tags:      len(want) > 0,
+		})
+		logErr(err, "failed to list objects")
+
+		objects = filterObjectsByTags(objects, want)
+
+		log.Logger().Info().Int("objects", len(objects)).Msg("objects listed successfully")
+
+		if bucketJSON {
+			err = writeJSON(cmd.OutOrStdout(), newBucketObjects(objects))
+		} else {
+			err = printBucketObjectsAsTable(cmd.OutOrStdout(), objects, bucketLong)
+		}
+		logErr(err, "failed to print objects")
+	},
+}
+
+var bucketStatCmd = &cobra.Command{
+	Use:   "stat <key>",
+	Short: "Shows all headers, metadata and tags of an object",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mc, err := newBucketClient(cfg)
+		logErr(err, "failed to setup MinIO client")
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		var d *minio.ObjectDetails
+		d, err = mc.Inspect(ctx, args[0])
+		logErr(err, "failed to get object details")
+
+		if bucketJSON {
+			err = writeJSON(cmd.OutOrStdout(), newBucketStat(d))
+			logErr(err, "failed to print object details")
+
+			return
+		}
+
+		printBucketStat(cmd, d)
+	},
+}
+
+var bucketDuCmd = &cobra.Command{
+	Use:   "du [prefix]",
+	Short: "Summarizes total size and object count by prefix, content type or month",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		by, err := parseDuGrouping(bucketBy)
+		logErr(err, "invalid --by flag")
+
+		if bucketDepth < 1 {
+			logErr(fmt.Errorf("depth must be at least 1, got %d", bucketDepth), "invalid --depth flag")
+		}
+
+		var mc *minio.Client
+		mc, err = newBucketClient(cfg)
+		logErr(err, "failed to setup MinIO client")
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		prefix := optionalArg(args)
+
+		var objects []minio.ObjectInfo
+		objects, err = mc.ListObjects(ctx, minio.ListOptions{
+			Prefix:    prefix,
+			Recursive: true,
+			Metadata:  by == duByType,
+			Tags:      false,
+		})
+		logErr(err, "failed to list objects")
+
+		usage := summarizeUsage(objects, by, prefix, bucketDepth)
+
+		if bucketJSON {
+			err = writeJSON(cmd.OutOrStdout(), usage)
+		} else {
+			err = printUsageAsTable(cmd.OutOrStdout(), by, usage)
+		}
+		logErr(err, "failed to print usage")
+	},
+}
+
+var bucketVersioningCmd = &cobra.Command{
+	Use:       "versioning [on|off]",
+	Short:     "Shows, enables or suspends versioning for the bucket",
+	Long:      "Shows, enables or suspends versioning for the bucket. Suspending keeps existing versions.",
+	Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"on", "off"},
+	Run: func(cmd *cobra.Command, args []string) {
+		mc, err := newBucketClient(cfg)
+		logErr(err, "failed to setup MinIO client")
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		if len(args) == 1 {
+			err = mc.SetVersioning(ctx, args[0] == "on")
+			logErr(err, "failed to set bucket versioning")
+
+			log.Logger().Info().Str("bucket", cfg.MinioBucketName).Str("versioning", args[0]).
+				Msg("bucket versioning set successfully")
+		}
+
+		var enabled bool
+		enabled, err = mc.Versioning(ctx)
+		logErr(err, "failed to get bucket versioning")
+
+		status := "off"
+		if enabled {
+			status = "on"
+		}
+
+		if bucketJSON {
+			err = writeJSON(cmd.OutOrStdout(), map[string]any{"bucket": cfg.MinioBucketName, "versioning": enabled})
+			logErr(err, "failed to print bucket versioning")
+
+			return
+		}
+
+		cmd.Printf("Versioning for %s is %s\n", cfg.MinioBucketName, status)
+	},
+}
+
+var (
+	bucketName      string
+	bucketJSON      bool
+	bucketRecursive bool
+	bucketLong      bool
+	bucketBy        string
+	bucketDepth     int
+	bucketTags      []string
+)
+
+var bucketConfigFlags = map[string]string{
+	"bucket": "minio_bucket_name",
+}
+
+func init() {
+	rootCmd.AddCommand(bucketCmd)
+	bucketCmd.AddCommand(bucketLsCmd, bucketStatCmd, bucketDuCmd, bucketVersioningCmd)
+
+	bucketCmd.PersistentFlags().
+		StringVar(&bucketName, "bucket", "", "override the MinIO bucket")
+	bucketCmd.PersistentFlags().
+		BoolVar(&bucketJSON, "json", false, "print the output as JSON")
+
+	bucketLsCmd.Flags().
+		BoolVarP(&bucketRecursive, "recursive", "r", false, "list objects in sub directories instead of the directories")
+	bucketLsCmd.Flags().
+		BoolVarP(&bucketLong, "long", "l", false, "also show content type, original file name and metadata")
+	bucketLsCmd.Flags().
+		StringArrayVar(&bucketTags, "tag", nil, "only list objects with this tag as key=value, can be repeated")
+
+	bucketDuCmd.Flags().
+		StringVar(&bucketBy, "by", duByPrefix, "group by prefix, type or month")
+	bucketDuCmd.Flags().
+		IntVar(&bucketDepth, "depth", 1, "number of path segments below the prefix to group by")
+}
+
+func optionalArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	return args[0]
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	err := enc.Encode(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	return nil
+}
+
+type bucketObject struct {
+	Key          string            `json:"key"`
+	Dir          bool              `json:"dir,omitempty"`
+	Size         int64             `json:"size"`
+	LastModified *time.Time        `json:"last_modified,omitempty"`
+	ContentType  string            `json:"content_type,omitempty"`
+	Owner        string            `json:"owner,omitempty"`
+	ETag         string            `json:"etag,omitempty"`
+	Filename     string            `json:"filename,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+}
+
+func newBucketObject(o minio.ObjectInfo) bucketObject {
+	var modified *time.Time
+	if !o.LastModified.IsZero() {
+		modified = &o.LastModified
+	}
+
+	return bucketObject{
+		Key:          o.Key,
+		Dir:          o.Dir,
+		Size:         o.Size,
+		LastModified: modified,
+		ContentType:  o.ContentType,
+		Owner:        o.Owner,
+		ETag:         o.ETag,
+		Filename:     o.Filename,
+		Metadata:     o.Metadata,
+		Tags:         o.Tags,
+	}
+}
+
+// filterObjectsByTags keeps the objects with all wanted tags, directories
+// have no tags and are dropped when filtering.
+func filterObjectsByTags(objects []minio.ObjectInfo, want map[string]string) []minio.ObjectInfo {
+	if len(want) == 0 {
+		return objects
+	}
+
+	var result []minio.ObjectInfo
+	for _, o := range objects {
+		if !o.Dir && minio.MatchTags(o.Tags, want) {
+			result = append(result, o)
+		}
+	}
+
+	return result
+}
+
+func newBucketObjects(objects []minio.ObjectInfo) []bucketObject {
+	result := make([]bucketObject, 0, len(objects))
+	for _, o := range objects {
+		result = append(result, newBucketObject(o))
+	}
+
+	return result
+}
+
+// printBucketObjectsAsTable prints content type and file name only for
+// long listings, other listings do not have them.
+func printBucketObjectsAsTable(w io.Writer, objects []minio.ObjectInfo, long bool) error {
+	if len(objects) == 0 {
+		return errors.New("no objects found")
+	}
+
+	table := tablewriter.NewWriter(w)
+	if long {
+		table.Header([]string{"Key", "Size", "Modified", "Owner", "Content Type", "Filename"})
+	} else {
+		table.Header([]string{"Key", "Size", "Modified", "Owner"})
+	}
+
+	for _, o := range objects {
+		row := []string{o.Key, "DIR", "", ""}
+		if !o.Dir {
+			row = []string{o.Key, preview.FormatSize(o.Size), o.LastModified.Format(time.RFC3339), o.Owner}
+		}
+
+		if long {
+			row = append(row, o.ContentType, o.Filename)
+		}
+
+		err := table.Append(row)
+		if err != nil {
+			return fmt.Errorf("failed to append row to table: %w", err)
+		}
+	}
+
+	err := table.Render()
+	if err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+
+	return nil
+}
+
+type bucketStat struct {
+	bucketObject
+
+	VersionID    string              `json:"version_id,omitempty"`
+	StorageClass string              `json:"storage_class,omitempty"`
+	Headers      map[string][]string `json:"headers"`
+	Tags         map[string]string   `json:"tags,omitempty"`
+}
+
+func newBucketStat(d *minio.ObjectDetails) bucketStat {
+	return bucketStat{
+		bucketObject: newBucketObject(d.ObjectInfo),
+		VersionID:    d.VersionID,
+		StorageClass: d.StorageClass,
+		Headers:      d.Headers,
+		Tags:         d.Tags,
+	}
+}
+
+func printBucketStat(cmd *cobra.Command, d *minio.ObjectDetails) {
+	cmd.Println("Object")
+	cmd.Println("------")
+	cmd.Printf("Key:\t\t%s\n", d.Key)
+	cmd.Printf("Size:\t\t%s (%d bytes)\n", preview.FormatSize(d.Size), d.Size)
+	cmd.Printf("Modified:\t%s\n", d.LastModified.Format(time.RFC3339))
+	cmd.Printf("Content Type:\t%s\n", d.ContentType)
+	cmd.Printf("ETag:\t\t%s\n", d.ETag)
+	cmd.Printf("Owner:\t\t%s\n", d.Owner)
+	cmd.Printf("Version ID:\t%s\n", d.VersionID)
+	cmd.Printf("Storage Class:\t%s\n", d.StorageClass)
+	cmd.Printf("Filename:\t%s\n", d.Filename)
+
+	cmd.Println()
+	cmd.Println("Headers")
+	cmd.Println("-------")
+
+	for _, name := range sortedKeys(d.Headers) {
+		cmd.Printf("%s: %s\n", name, strings.Join(d.Headers[name], ", "))
+	}
+
+	cmd.Println()
+	cmd.Println("Tags")
+	cmd.Println("----")
+
+	if d.Tags == nil {
+		cmd.Println("object tagging is not supported by the provider")
+		return
+	}
+
+	for _, key := range sortedKeys(d.Tags) {
+		cmd.Printf("%s=%s\n", key, d.Tags[key])
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+const (
+	duByPrefix = "prefix"
+	duByType   = "type"
+	duByMonth  = "month"
+)
+
+func parseDuGrouping(by string) (string, error) {
+	switch by {
+	case duByPrefix, duByType, duByMonth:
+		return by, nil
+	default:
+		return "", fmt.Errorf("unknown grouping %s, must be one of prefix, type or month", by)
+	}
+}
+
+type usageGroup struct {
+	Group   string `json:"group"`
+	Objects int    `json:"objects"`
+	Size    int64  `json:"size"`
+}
+
+type usage struct {
+	Groups []usageGroup `json:"groups"`
+	Total  usageGroup   `json:"total"`
+}
+
+// summarizeUsage groups objects, the largest group comes first.
+func summarizeUsage(objects []minio.ObjectInfo, by string, prefix string, depth int) usage {
+	groups := make(map[string]*usageGroup)
+	total := usageGroup{Group: "total", Objects: 0, Size: 0}
+
+	for _, o := range objects {
+		name := usageGroupName(o, by, prefix, depth)
+
+		g, ok := groups[name]
+		if !ok {
+			g = &usageGroup{Group: name, Objects: 0, Size: 0}
+			groups[name] = g
+		}
+
+		g.Objects++
+		g.Size += o.Size
+		total.Objects++
+		total.Size += o.Size
+	}
+
+	result := usage{Groups: make([]usageGroup, 0, len(groups)), Total: total}
+	for _, g := range groups {
+		result.Groups = append(result.Groups, *g)
+	}
+
+	sort.Slice(result.Groups, func(i, j int) bool {
+		if result.Groups[i].Size != result.Groups[j].Size {
+			return result.Groups[i].Size > result.Groups[j].Size
+		}
+
+		return result.Groups[i].Group < result.Groups[j].Group
+	})
+
+	return result
+}
+
+func usageGroupName(o minio.ObjectInfo, by string, prefix string, depth int) string {
+	switch by {
+	case duByType:
+		if o.ContentType == "" {
+			return "unknown"
+		}
+
+		// Parameters like the charset do not make a different type.
+		t, _, _ := strings.Cut(o.ContentType, ";")
+
+		return strings.TrimSpace(t)
+	case duByMonth:
+		return o.LastModified.Format("2006-01")
+	default:
+		rel := strings.TrimPrefix(o.Key, prefix)
+
+		// Only directories group objects, the object name itself is dropped.
+		segments := strings.Split(rel, "/")
+		segments = segments[:len(segments)-1]
+		if len(segments) == 0 {
+			return prefix + "."
+		}
+
+		return prefix + strings.Join(segments[:min(depth, len(segments))], "/") + "/"
+	}
+}
+
+func printUsageAsTable(w io.Writer, by string, u usage) error {
+	if len(u.Groups) == 0 {
+		return errors.New("no objects found")
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.Header([]string{strings.ToUpper(by[:1]) + by[1:], "Objects", "Size"})
+
+	for _, g := range u.Groups {
+		err := table.Append([]string{g.Group, strconv.Itoa(g.Objects), preview.FormatSize(g.Size)})
+		if err != nil {
+			return fmt.Errorf("failed to append row to table: %w", err)
+		}
+	}
+
+	table.Footer([]string{"Total", strconv.Itoa(u.Total.Objects), preview.FormatSize(u.Total.Size)})
+
+	err := table.Render()
+	if err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+
+	return nil
+}
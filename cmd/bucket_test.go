@@ -0,0 +1,107 @@
+package cmd_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/devusSs/minly/cmd"
+	"github.com/devusSs/minly/internal/minio"
+)
+
+func TestSummarizeUsage(t *testing.T) {
+	t.Parallel()
+
+	objects := []minio.ObjectInfo{
+		object("files/a/one.pdf", 10, "application/pdf", "2025-01-03"),
+		object("files/a/b/two.txt", 20, "text/plain; charset=utf-8", "2025-01-20"),
+		object("files/c/three.txt", 40, "text/plain", "2025-02-01"),
+		object("files/root.bin", 5, "", "2025-02-11"),
+	}
+
+	tests := []struct {
+		name   string
+		by     string
+		prefix string
+		depth  int
+		want   []cmd.UsageGroup
+	}{
+		{
+			name:   "by prefix",
+			by:     "prefix",
+			prefix: "files/",
+			depth:  1,
+			want: []cmd.UsageGroup{
+				{Group: "files/c/", Objects: 1, Size: 40},
+				{Group: "files/a/", Objects: 2, Size: 30},
+				{Group: "files/.", Objects: 1, Size: 5},
+			},
+		},
+		{
+			name:   "by prefix with depth",
+			by:     "prefix",
+			prefix: "files/",
+			depth:  2,
+			want: []cmd.UsageGroup{
+				{Group: "files/c/", Objects: 1, Size: 40},
+				{Group: "files/a/b/", Objects: 1, Size: 20},
+				{Group: "files/a/", Objects: 1, Size: 10},
+				{Group: "files/.", Objects: 1, Size: 5},
+			},
+		},
+		{
+			name:   "by type ignores parameters",
+			by:     "type",
+			prefix: "",
+			depth:  1,
+			want: []cmd.UsageGroup{
+				{Group: "text/plain", Objects: 2, Size: 60},
+				{Group: "application/pdf", Objects: 1, Size: 10},
+				{Group: "unknown", Objects: 1, Size: 5},
+			},
+		},
+		{
+			name:   "by month",
+			by:     "month",
+			prefix: "",
+			depth:  1,
+			want: []cmd.UsageGroup{
+				{Group: "2025-02", Objects: 2, Size: 45},
+				{Group: "2025-01", Objects: 2, Size: 30},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := cmd.SummarizeUsage(objects, tt.by, tt.prefix, tt.depth)
+			if !slices.Equal(got.Groups, tt.want) {
+				t.Errorf("Groups = %v, want %v", got.Groups, tt.want)
+			}
+
+			wantTotal := cmd.UsageGroup{Group: "total", Objects: len(objects), Size: 75}
+			if got.Total != wantTotal {
+				t.Errorf("Total = %v, want %v", got.Total, wantTotal)
+			}
+		})
+	}
+}
+
+func object(key string, size int64, contentType string, day string) minio.ObjectInfo {
+	modified, _ := time.Parse(time.DateOnly, day)
+
+	return minio.ObjectInfo{
+		Key:          key,
+		Size:         size,
+		ContentType:  contentType,
+		LastModified: modified,
+		ETag:         "",
+		Owner:        "",
+		Dir:          false,
+		Filename:     "",
+		Metadata:     nil,
+		Tags:         nil,
+	}
+}
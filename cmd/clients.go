@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/devusSs/minly/internal/config"
+	"github.com/devusSs/minly/internal/log"
+	"github.com/devusSs/minly/internal/minio"
+	"github.com/devusSs/minly/internal/secret"
+	"github.com/devusSs/minly/internal/tlsconfig"
+	"github.com/devusSs/minly/internal/transport"
+	"github.com/devusSs/minly/internal/yourls"
+)
+
+// newUploadClients creates the MinIO and YOURLS clients used for uploads
+// and applies the bucket, prefix and link mode of the config.
+func newUploadClients(c *config.Config) (*minio.Client, *yourls.Client, error) {
+	mc, err := newBucketClient(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = mc.SetPrefix(c.MinioObjectPrefix)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to set MinIO object prefix: %w", err)
+	}
+
+	if c.MinioLinkMode == "public" {
+		err = mc.SetPublic(c.MinioPublicPrefix, c.MinioPublicBaseURLParsed())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to enable public links: %w", err)
+		}
+
+		log.Logger().Info().
+			Str("minio_public_prefix", c.MinioPublicPrefix).
+			Str("minio_public_base_url", c.MinioPublicBaseURL).
+			Msg("public permanent link mode enabled")
+
+		if !mc.PublicPolicy() {
+			log.Logger().Info().Str("provider", mc.Provider().Name).
+				Msg("provider has no bucket policies, minio_public_base_url has to serve the public prefix publicly")
+		}
+	}
+
+	var yourlsSignature string
+	yourlsSignature, err = getSecret(secret.YOURLSignature)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get YOURLS signature: %w", err)
+	}
+
+	log.Logger().Info().Msg("got YOURLS signature successfully")
+
+	var yc *yourls.Client
+	yc, err = newYOURLSClient(c, yourlsSignature)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create YOURLS client: %w", err)
+	}
+
+	log.Logger().Info().Msg("YOURLS client created successfully")
+
+	return mc, yc, nil
+}
+
+// newBucketClient creates a MinIO client set up for the configured bucket,
+// for commands that do not create short links.
+func newBucketClient(c *config.Config) (*minio.Client, error) {
+	err := setupSecretBackend(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup secret backend: %w", err)
+	}
+
+	var minioAccessKey, minioAccessSecret string
+	minioAccessKey, minioAccessSecret, err = getMinioKeys(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MinIO keys: %w", err)
+	}
+
+	log.Logger().Info().Str("source", c.MinioCredentialSource).
+		Msg("got MinIO credentials successfully")
+
+	var mc *minio.Client
+	mc, err = newMinioClient(c, minioAccessKey, minioAccessSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MinIO client: %w", err)
+	}
+
+	log.Logger().Info().
+		Str("minio_endpoint", c.MinioEndpoint).
+		Str("minio_provider", mc.Provider().Name).
+		Str("minio_credential_source", c.MinioCredentialSource).
+		Bool("minio_use_ssl", c.MinioUseSSL).
+		Str("minio_region", c.MinioRegion).
+		Msg("MinIO client created successfully")
+
+	err = mc.Setup(c.MinioBucketName, c.MinioRegion, c.MinioLinkExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup MinIO client: %w", err)
+	}
+
+	log.Logger().Info().
+		Str("minio_bucket_name", c.MinioBucketName).
+		Str("minio_region", c.MinioRegion).
+		Str("minio_link_expiry", c.MinioLinkExpiry.String()).
+		Msg("MinIO client setup successfully")
+
+	return mc, nil
+}
+
+func newMinioClient(c *config.Config, accessKey string, accessSecret string) (*minio.Client, error) {
+	provider, err := minio.NewProvider(c.MinioProvider, c.MinioAddressing, c.MinioSignature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MinIO provider: %w", err)
+	}
+
+	var tlsConfig *tls.Config
+	tlsConfig, err = newMinioTLSConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var source minio.CredentialSource
+	source, err = minio.ParseCredentialSource(c.MinioCredentialSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MinIO credential source: %w", err)
+	}
+
+	var mc *minio.Client
+	mc, err = minio.NewClient(minio.Options{
+		Endpoint: c.MinioEndpoint,
+		Credentials: minio.Credentials{
+			Source:               source,
+			AccessKey:            accessKey,
+			AccessSecret:         accessSecret,
+			File:                 c.MinioCredentialFile,
+			Profile:              c.MinioCredentialProfile,
+			STSEndpoint:          c.MinioSTSEndpoint,
+			RoleARN:              c.MinioRoleARN,
+			RoleSessionName:      c.MinioRoleSessionName,
+			WebIdentityTokenFile: c.MinioWebIdentityTokenFile,
+			Duration:             c.MinioCredentialDuration,
+		},
+		UseSSL:    c.MinioUseSSL,
+		Region:    c.MinioRegion,
+		Provider:  provider,
+		Transport: newTransport(c, tlsConfig),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MinIO client: %w", err)
+	}
+
+	return mc, nil
+}
+
+func newMinioTLSConfig(c *config.Config) (*tls.Config, error) {
+	return newTLSConfig("MinIO", tlsconfig.Options{
+		CAFile:             c.MinioTLSCAFile,
+		CertFile:           c.MinioTLSCertFile,
+		KeyPEM:             "",
+		MinVersion:         c.MinioTLSMinVersion,
+		ServerName:         c.MinioTLSServerName,
+		InsecureSkipVerify: c.MinioTLSInsecureSkipVerify,
+	}, secret.MinioTLSClientKey)
+}
+
+// newMinioHTTPClient creates a plain HTTP client with the MinIO TLS settings,
+// for requests to presigned and public links.
+func newMinioHTTPClient(c *config.Config) (*http.Client, error) {
+	tlsConfig, err := newMinioTLSConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport:     newTransport(c, tlsConfig),
+		CheckRedirect: nil,
+		Jar:           nil,
+		Timeout:       0,
+	}, nil
+}
+
+func minioNeedsKeys(c *config.Config) bool {
+	return minio.CredentialSource(c.MinioCredentialSource).NeedsKeys()
+}
+
+func requiredSecrets(c *config.Config) []secret.Key {
+	if minioNeedsKeys(c) {
+		return secret.Keys()
+	}
+
+	// Temporary credentials come from outside minly, only YOURLS needs a stored secret.
+	return []secret.Key{secret.YOURLSignature}
+}
+
+func getMinioKeys(c *config.Config) (string, string, error) {
+	if !minioNeedsKeys(c) {
+		log.Logger().Debug().Str("source", c.MinioCredentialSource).
+			Msg("MinIO credential source does not use stored keys")
+		return "", "", nil
+	}
+
+	accessKey, err := getSecret(secret.MinioAccessKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get MinIO access key: %w", err)
+	}
+
+	var accessSecret string
+	accessSecret, err = getSecret(secret.MinioAccessSecret)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get MinIO access secret: %w", err)
+	}
+
+	return accessKey, accessSecret, nil
+}
+
+func newYOURLSClient(c *config.Config, signature string) (*yourls.Client, error) {
+	tlsConfig, err := newTLSConfig("YOURLS", tlsconfig.Options{
+		CAFile:             c.YOURLSTLSCAFile,
+		CertFile:           c.YOURLSTLSCertFile,
+		KeyPEM:             "",
+		MinVersion:         c.YOURLSTLSMinVersion,
+		ServerName:         c.YOURLSTLSServerName,
+		InsecureSkipVerify: c.YOURLSTLSInsecureSkipVerify,
+	}, secret.YOURLSTLSClientKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var yc *yourls.Client
+	yc, err = yourls.NewClient(c.YOURLSEndpoint.String(), signature, newTransport(c, tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create YOURLS client: %w", err)
+	}
+
+	return yc, nil
+}
+
+func newTLSConfig(service string, opts tlsconfig.Options, clientKey secret.Key) (*tls.Config, error) {
+	if opts.IsZero() {
+		return nil, nil //nolint:nilnil // No custom TLS settings means the library defaults are used.
+	}
+
+	if opts.CertFile != "" {
+		key, err := getSecret(clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s TLS client key: %w", service, err)
+		}
+
+		opts.KeyPEM = key
+	}
+
+	// Printed to stderr as well since logging may be disabled, e.g. by --test.
+	if opts.InsecureSkipVerify {
+		const warning = "!!! TLS CERTIFICATE VERIFICATION IS DISABLED, connections can be intercepted, only use this for testing !!!"
+
+		log.Logger().Warn().Str("service", service).Msg(warning)
+
+		_, err := fmt.Fprintf(os.Stderr, "WARNING: %s: %s\n", service, warning)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write TLS warning: %w", err)
+		}
+	}
+
+	tlsConfig, err := tlsconfig.New(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s TLS config: %w", service, err)
+	}
+
+	return tlsConfig, nil
+}
+
+func newTransport(c *config.Config, tlsConfig *tls.Config) http.RoundTripper {
+	return transport.New(transport.Options{
+		ProxyURL:              c.HTTPProxyURL(),
+		DialTimeout:           c.HTTPDialTimeout,
+		TLSHandshakeTimeout:   c.HTTPTLSHandshakeTimeout,
+		ResponseHeaderTimeout: c.HTTPResponseHeaderTimeout,
+		KeepAlive:             c.HTTPKeepAlive,
+		TLS:                   tlsConfig,
+	})
+}
@@ -0,0 +1,439 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/devusSs/minly/internal/config"
+	"github.com/devusSs/minly/internal/log"
+	"github.com/devusSs/minly/internal/minio"
+	"github.com/devusSs/minly/internal/preview"
+	"github.com/devusSs/minly/internal/storage"
+	"github.com/devusSs/minly/internal/yourls"
+)
+
+var collectionCmd = &cobra.Command{
+	Use:   "collection",
+	Short: "Share a set of uploaded files using one short link",
+	PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		err := log.Setup()
+		checkErr(err, "failed to setup log package")
+
+		go func() {
+			err = log.CleanOld()
+			if err != nil {
+				log.Logger().Error().Err(err).Msg("failed to clean old log files")
+			}
+		}()
+
+		fs, err = storage.NewFileStore()
+		logErr(err, "failed to create file store")
+	},
+	PersistentPostRun: func(_ *cobra.Command, _ []string) {
+		err := log.Flush()
+		checkErr(err, "failed to flush log package")
+	},
+	Run: func(_ *cobra.Command, _ []string) {
+		err := printCollectionsAsTable()
+		logErr(err, "failed to print collections as table")
+	},
+}
+
+var collectionCreateCmd = &cobra.Command{
+	Use:   "create <name> <files...>",
+	Short: "Uploads files and shares them as a collection",
+	Args:  cobra.MinimumNArgs(collectionMinArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, filePaths := args[0], args[1:]
+
+		err := storage.ValidateCollectionName(name)
+		logErr(err, "invalid collection name")
+
+		var collections []storage.Collection
+		collections, err = fs.LoadCollections()
+		logErr(err, "failed to load collections")
+
+		if slices.ContainsFunc(collections, func(c storage.Collection) bool { return c.Name == name }) {
+			logErr(fmt.Errorf("collection %s already exists", name), "failed to create collection")
+		}
+
+		mc, yc := setupCollectionClients(cmd, nil, filePaths)
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		col := storage.NewCollection(name, cfg.MinioBucketName)
+		col.Permanent = mc.Public()
+		col.IndexKey = mc.ObjectKey("collections/" + col.ID + "/index.html")
+		col.ManifestKey = mc.ObjectKey("collections/" + col.ID + "/manifest.json")
+
+		err = addCollectionFiles(ctx, mc, col, filePaths)
+		logErr(err, "failed to upload collection files")
+
+		var index *minio.Object
+		index, err = publishCollection(ctx, mc, col)
+		logErr(err, "failed to publish collection index")
+
+		col.IndexLink = index.URL.String()
+		col.IndexLinkExpires = index.Expires
+
+		col.YOURLSLink, err = shareObject(ctx, mc, yc, index, collectionNoClip)
+		logErr(err, "failed to share collection index")
+
+		err = fs.SaveCollection(col)
+		logErr(err, "failed to save collection")
+
+		log.Logger().Info().Str("collection", col.Name).Int("files", len(col.Files)).
+			Str("short_url", col.YOURLSLink).Msg("collection created successfully")
+	},
+}
+
+var collectionAddCmd = &cobra.Command{
+	Use:   "add <name> <files...>",
+	Short: "Uploads files and adds them to a collection",
+	Args:  cobra.MinimumNArgs(collectionMinArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		col, err := fs.LoadCollection(args[0])
+		logErr(err, "failed to load collection")
+
+		for _, filePath := range args[1:] {
+			i := col.File(filepath.Base(filePath))
+			if i >= 0 {
+				logErr(
+					fmt.Errorf("%s is already in collection %s", col.Files[i].Name, col.Name),
+					"remove it first to replace it",
+				)
+			}
+		}
+
+		warnExpiredCollection(col)
+
+		mc, _ := setupCollectionClients(cmd, col, args[1:])
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		err = addCollectionFiles(ctx, mc, col, args[1:])
+		logErr(err, "failed to upload collection files")
+
+		_, err = publishCollection(ctx, mc, col)
+		logErr(err, "failed to publish collection index")
+
+		err = fs.SaveCollection(col)
+		logErr(err, "failed to save collection")
+
+		log.Logger().Info().Str("collection", col.Name).Int("files", len(col.Files)).
+			Str("short_url", col.YOURLSLink).Msg("files added to collection successfully")
+	},
+}
+
+var collectionRemoveCmd = &cobra.Command{
+	Use:   "remove <name> <files...>",
+	Short: "Removes files from a collection and deletes them from MinIO",
+	Args:  cobra.MinimumNArgs(collectionMinArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		col, err := fs.LoadCollection(args[0])
+		logErr(err, "failed to load collection")
+
+		// Resolve all names first so nothing is deleted if one is wrong.
+		var removed []storage.CollectionFile
+		for _, nameOrKey := range args[1:] {
+			i := col.File(nameOrKey)
+			if i < 0 {
+				logErr(fmt.Errorf("%s is not in collection %s", nameOrKey, col.Name), "failed to remove file")
+			}
+
+			removed = append(removed, col.Files[i])
+			col.Files = slices.Delete(col.Files, i, i+1)
+		}
+
+		warnExpiredCollection(col)
+
+		mc, _ := setupCollectionClients(cmd, col, nil)
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		// Publish the index without the files first so it never links to
+		// deleted objects if a later step fails.
+		_, err = publishCollection(ctx, mc, col)
+		logErr(err, "failed to publish collection index")
+
+		err = fs.SaveCollection(col)
+		logErr(err, "failed to save collection")
+
+		for _, f := range removed {
+			err = mc.RemoveObject(ctx, f.ObjectKey)
+			logErr(err, "file removed from collection but failed to delete "+f.ObjectKey+" from MinIO")
+
+			log.Logger().Info().Str("name", f.Name).Str("object_key", f.ObjectKey).
+				Msg("file removed from collection")
+		}
+	},
+}
+
+var collectionRenewCmd = &cobra.Command{
+	Use:   "renew <name>",
+	Short: "Creates fresh links for a collection and updates its short link",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		col, err := fs.LoadCollection(args[0])
+		logErr(err, "failed to load collection")
+
+		if col.Permanent {
+			log.Logger().Info().Str("collection", col.Name).Str("short_url", col.YOURLSLink).
+				Msg("collection uses public links, they do not expire")
+			return
+		}
+
+		mc, yc := setupCollectionClients(cmd, col, nil)
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		var index *minio.Object
+		index, err = publishCollection(ctx, mc, col)
+		logErr(err, "failed to publish collection index")
+
+		col.IndexLink = index.URL.String()
+		col.IndexLinkExpires = index.Expires
+
+		// Keep the short link and point it to the new index URL, a new
+		// short link is only created if YOURLS cannot update it.
+		err = yc.Update(ctx, col.YOURLSLink, index.URL.String())
+		if errors.Is(err, yourls.ErrUpdateUnsupported) {
+			log.Logger().Warn().Err(err).Msg("cannot update short link, creating a new one")
+
+			col.YOURLSLink, err = shareObject(ctx, mc, yc, index, collectionNoClip)
+			logErr(err, "failed to share collection index")
+		}
+		logErr(err, "failed to update YOURLS target")
+
+		err = fs.SaveCollection(col)
+		logErr(err, "failed to save collection")
+
+		log.Logger().Info().Str("collection", col.Name).Str("short_url", col.YOURLSLink).
+			Str("expires", col.IndexLinkExpires.Format(time.RFC3339)).Msg("collection renewed successfully")
+	},
+}
+
+// The collection name and at least one file.
+const collectionMinArgs = 2
+
+var (
+	collectionNoClip bool
+	collectionExpiry time.Duration
+	collectionPublic bool
+	collectionForce  bool
+
+	collectionKeepMetadata bool
+)
+
+func init() {
+	rootCmd.AddCommand(collectionCmd)
+	collectionCmd.AddCommand(collectionCreateCmd)
+	collectionCmd.AddCommand(collectionAddCmd)
+	collectionCmd.AddCommand(collectionRemoveCmd)
+	collectionCmd.AddCommand(collectionRenewCmd)
+
+	collectionCmd.PersistentFlags().
+		BoolVar(&collectionNoClip, "no-clip", false, "do not write short URL to clipboard")
+	collectionCmd.PersistentFlags().
+		DurationVar(&collectionExpiry, "expiry", 0, "override the MinIO link expiry")
+	collectionCmd.PersistentFlags().
+		BoolVar(&collectionForce, "force", false, "upload despite upload policy violations after confirmation")
+	collectionCmd.PersistentFlags().
+		BoolVar(&collectionKeepMetadata, "keep-metadata", false, "do not strip EXIF, XMP and IPTC metadata from images")
+
+	collectionCreateCmd.Flags().
+		BoolVar(&collectionPublic, "public", false, "upload to the public prefix and create permanent links")
+
+	collectionCreateCmd.MarkFlagsMutuallyExclusive("public", "expiry")
+}
+
+// setupCollectionClients loads the config, checks the files against the
+// upload policy and creates the clients. Existing collections keep their
+// bucket and link mode.
+func setupCollectionClients(
+	cmd *cobra.Command,
+	col *storage.Collection,
+	filePaths []string,
+) (*minio.Client, *yourls.Client) {
+	overrides, err := flagOverrides(cmd, map[string]string{"expiry": "minio_link_expiry"})
+	logErr(err, "failed to read config flags")
+
+	switch {
+	case col != nil && col.Permanent:
+		overrides["minio_bucket_name"] = col.Bucket
+		overrides["minio_link_mode"] = "public"
+	case col != nil:
+		overrides["minio_bucket_name"] = col.Bucket
+		overrides["minio_link_mode"] = "presigned"
+	case collectionPublic:
+		overrides["minio_link_mode"] = "public"
+	}
+
+	cfg, err = config.Load(overrides)
+	logErr(err, "failed to load config")
+
+	log.Logger().Info().Any("config", cfg).
+		Msg("config loaded successfully")
+
+	for _, filePath := range filePaths {
+		var info os.FileInfo
+		info, err = os.Stat(filePath)
+		logErr(err, "failed to stat file")
+
+		if info.IsDir() {
+			logErr(fmt.Errorf("%s is a directory", filePath), "collections can only contain files")
+		}
+
+		err = checkUploadPolicy(cfg, filePath, collectionForce)
+		logErr(err, "upload blocked by policy")
+	}
+
+	mc, yc, err := newUploadClients(cfg)
+	logErr(err, "failed to setup clients")
+
+	return mc, yc
+}
+
+func addCollectionFiles(ctx context.Context, mc *minio.Client, col *storage.Collection, filePaths []string) error {
+	for _, filePath := range filePaths {
+		up, err := uploadPrepared(ctx, cfg, mc, filePath, prepareOptions{
+			keepMetadata: collectionKeepMetadata,
+			withOriginal: false,
+			replace:      false,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upload %s: %w", filePath, err)
+		}
+
+		col.Files = append(col.Files, storage.CollectionFile{
+			Name:        up.name,
+			ObjectKey:   up.object.Key,
+			ContentType: up.object.ContentType,
+			Size:        up.object.Size,
+			Added:       time.Now(),
+		})
+
+		log.Logger().Info().Str("collection", col.Name).Str("name", up.name).Str("object_key", up.object.Key).
+			Msg("file uploaded to collection")
+	}
+
+	return nil
+}
+
+// publishCollection creates fresh links for all files and uploads the
+// manifest and index page. Both keep their keys, existing links to the
+// index stay valid and show the new contents.
+func publishCollection(ctx context.Context, mc *minio.Client, col *storage.Collection) (*minio.Object, error) {
+	idx := preview.Index{
+		Name:        col.Name,
+		Updated:     time.Now(),
+		Files:       make([]preview.IndexEntry, 0, len(col.Files)),
+		ManifestURL: "",
+	}
+
+	for _, f := range col.Files {
+		obj, err := mc.Link(ctx, f.ObjectKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create link for %s: %w", f.Name, err)
+		}
+
+		idx.Files = append(idx.Files, preview.IndexEntry{
+			Name:        f.Name,
+			URL:         obj.URL.String(),
+			ContentType: f.ContentType,
+			Size:        f.Size,
+			Expires:     obj.Expires,
+		})
+	}
+
+	manifest, err := preview.RenderManifest(idx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render manifest: %w", err)
+	}
+
+	var manifestObj *minio.Object
+	manifestObj, err = mc.PutData(ctx, col.ManifestKey, manifest, "application/json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload manifest: %w", err)
+	}
+
+	idx.ManifestURL = manifestObj.URL.String()
+
+	var page []byte
+	page, err = preview.RenderIndex(idx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render index page: %w", err)
+	}
+
+	var index *minio.Object
+	index, err = mc.PutData(ctx, col.IndexKey, page, "text/html; charset=utf-8")
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload index page: %w", err)
+	}
+
+	col.Updated = idx.Updated
+
+	log.Logger().Info().Str("collection", col.Name).Str("index_key", col.IndexKey).
+		Int("files", len(col.Files)).Msg("collection index published")
+
+	return index, nil
+}
+
+func warnExpiredCollection(col *storage.Collection) {
+	if col.Expired() {
+		log.Logger().Warn().Str("collection", col.Name).
+			Msg("the collection link has expired, run minly collection renew to share it again")
+	}
+}
+
+func printCollectionsAsTable() error {
+	collections, err := fs.LoadCollections()
+	if err != nil {
+		return fmt.Errorf("failed to load collections: %w", err)
+	}
+
+	if len(collections) == 0 {
+		return errors.New("no collections found")
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Name", "Files", "Updated", "Link Expires", "YOURLS Link"})
+
+	for _, c := range collections {
+		expires := "never"
+		if !c.Permanent {
+			expires = c.IndexLinkExpires.Format(time.RFC3339)
+		}
+
+		err = table.Append([]string{
+			c.Name,
+			fmt.Sprintf("%d", len(c.Files)),
+			c.Updated.Format(time.RFC3339),
+			expires,
+			c.YOURLSLink,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to append row to table: %w", err)
+		}
+	}
+
+	err = table.Render()
+	if err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+
+	return nil
+}
@@ -32,11 +32,19 @@ var configCmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, _ []string) {
 		var err error
-		cfg, err = config.Read()
-		logErr(err, "failed to read config")
+		cfg, err = config.Load(nil)
+		logErr(err, "failed to load config")
 
 		log.Logger().Debug().Msg("configuration loaded successfully")
 
+		err = setupSecretBackend(cfg)
+		logErr(err, "failed to setup secret backend")
+
+		if configExplain {
+			printConfigExplanation(cmd)
+			return
+		}
+
 		var minioAccessKey, minioAccessSecret, yourlsSignature string
 
 		if configShowSensitive {
@@ -62,11 +70,15 @@ var configCmd = &cobra.Command{
 		cmd.Printf("Created At:\t\t%s\n", cfg.CreatedAt.Format(time.RFC3339))
 		cmd.Printf("Updated At:\t\t%s\n", cfg.UpdatedAt.Format(time.RFC3339))
 		cmd.Printf("MinIO Endpoint:\t\t%s\n", cfg.MinioEndpoint)
+		cmd.Printf("MinIO Provider:\t\t%s\n", cfg.MinioProvider)
+		cmd.Printf("MinIO Credentials:\t%s\n", cfg.MinioCredentialSource)
 		cmd.Printf("MinIO Use SSL:\t\t%t\n", cfg.MinioUseSSL)
 		cmd.Printf("MinIO Bucket Name:\t%s\n", cfg.MinioBucketName)
 		cmd.Printf("MinIO Region:\t\t%s\n", cfg.MinioRegion)
+		cmd.Printf("MinIO Link Mode:\t%s\n", cfg.MinioLinkMode)
 		cmd.Printf("MinIO Link Expiry:\t%s\n", cfg.MinioLinkExpiry.String())
 		cmd.Printf("YOURLS Endpoint:\t%s\n", cfg.YOURLSEndpoint)
+		cmd.Printf("Secret Backend:\t\t%s\n", secret.Current().Name())
 
 		if configShowSensitive {
 			log.Logger().Debug().Msg("printing sensitive information")
@@ -81,7 +93,10 @@ var configCmd = &cobra.Command{
 	},
 }
 
-var configShowSensitive bool
+var (
+	configShowSensitive bool
+	configExplain       bool
+)
 
 var configDeleteCmd = &cobra.Command{
 	Use:   "delete",
@@ -94,6 +109,9 @@ var configDeleteCmd = &cobra.Command{
 		log.Logger().Debug().Msg("configuration loaded successfully")
 
 		if configDeleteSecrets {
+			err = setupSecretBackend(cfg)
+			logErr(err, "failed to setup secret backend")
+
 			err = secret.DeleteAll()
 			logErr(err, "failed to delete secrets")
 
@@ -114,6 +132,10 @@ func init() {
 
 	configCmd.Flags().
 		BoolVar(&configShowSensitive, "show-sensitive", false, "Show secrets as well as the configuration")
+	configCmd.Flags().
+		BoolVar(&configExplain, "explain", false, "Show where each effective config value came from")
+
+	configCmd.MarkFlagsMutuallyExclusive("show-sensitive", "explain")
 
 	configCmd.AddCommand(configDeleteCmd)
 
@@ -121,6 +143,33 @@ func init() {
 		BoolVar(&configDeleteSecrets, "secrets", false, "Delete secrets in addition to the configuration")
 }
 
+func setupSecretBackend(c *config.Config) error {
+	b, err := newSecretBackend(c, c.SecretBackend)
+	if err != nil {
+		return err
+	}
+
+	secret.SetBackend(b)
+
+	return nil
+}
+
+func newSecretBackend(c *config.Config, name string) (secret.Backend, error) {
+	b, err := secret.NewBackend(name, secret.Options{
+		FilePath:   "",
+		Passphrase: "",
+		Command:    c.SecretCommand,
+		SetCommand: c.SecretSetCommand,
+
+		MissingExitCode: c.SecretMissingExitCode,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s secret backend: %w", name, err)
+	}
+
+	return b, nil
+}
+
 func getSecret(key secret.Key) (string, error) {
 	value, err := secret.Load(key)
 	if err != nil {
@@ -129,3 +178,64 @@ func getSecret(key secret.Key) (string, error) {
 
 	return value, nil
 }
+
+func printConfigExplanation(cmd *cobra.Command) {
+	cmd.Println("Effective configuration")
+	cmd.Println("-----------------------")
+
+	explanations := cfg.Explain()
+
+	width := 0
+	for _, e := range explanations {
+		width = max(width, len(e.Key))
+	}
+
+	for _, e := range explanations {
+		source := string(e.Source)
+		if e.Source == config.SourceFile {
+			source += " (" + cfg.FilePath() + ")"
+		}
+
+		if e.Source == config.SourceEnv {
+			source += " (" + e.Env + ")"
+		}
+
+		cmd.Printf("%-*s %-40s %s\n", width, e.Key, e.Value, source)
+	}
+}
+
+func flagOverrides(cmd *cobra.Command, keys map[string]string) (map[string]any, error) {
+	overrides := make(map[string]any)
+
+	for name, key := range keys {
+		if !cmd.Flags().Changed(name) {
+			continue
+		}
+
+		var value any
+		var err error
+
+		switch cmd.Flags().Lookup(name).Value.Type() {
+		case "string":
+			value, err = cmd.Flags().GetString(name)
+		case "int":
+			value, err = cmd.Flags().GetInt(name)
+		case "int64":
+			value, err = cmd.Flags().GetInt64(name)
+		case "bool":
+			value, err = cmd.Flags().GetBool(name)
+		case "duration":
+			value, err = cmd.Flags().GetDuration(name)
+		default:
+			err = fmt.Errorf("unsupported flag type for --%s", name)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get flag --%s: %w", name, err)
+		}
+
+		overrides[key] = value
+	}
+
+	return overrides, nil
+}
@@ -0,0 +1,457 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/devusSs/minly/internal/clipboard"
+	"github.com/devusSs/minly/internal/config"
+	"github.com/devusSs/minly/internal/doctor"
+	"github.com/devusSs/minly/internal/lastrun"
+	"github.com/devusSs/minly/internal/log"
+	"github.com/devusSs/minly/internal/minio"
+	"github.com/devusSs/minly/internal/paths"
+	"github.com/devusSs/minly/internal/secret"
+	"github.com/devusSs/minly/internal/storage"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Checks the environment, config, secrets and services minly depends on",
+	PreRun: func(_ *cobra.Command, _ []string) {
+		err := log.Setup()
+		checkErr(err, "failed to setup log package")
+
+		go func() {
+			err = log.CleanOld()
+			if err != nil {
+				log.Logger().Error().Err(err).Msg("failed to clean old log files")
+			}
+		}()
+	},
+	PostRun: func(_ *cobra.Command, _ []string) {
+		err := log.Flush()
+		checkErr(err, "failed to flush log package")
+	},
+	Run: func(cmd *cobra.Command, _ []string) {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		results := doctor.Run(ctx, doctorChecks(), doctorTimeout)
+
+		for _, r := range results {
+			log.Logger().Debug().Any("result", r).Msg("doctor check completed")
+		}
+
+		var err error
+		if doctorJSON {
+			err = doctor.WriteJSON(cmd.OutOrStdout(), results)
+		} else {
+			err = doctor.WriteTable(cmd.OutOrStdout(), results)
+		}
+		logErr(err, "failed to print doctor results")
+
+		failed := doctor.Failed(results)
+		if failed == 0 {
+			return
+		}
+
+		log.Logger().Error().Int("failed", failed).Msg("doctor found failing checks")
+
+		err = log.Flush()
+		checkErr(err, "failed to flush log package")
+
+		os.Exit(1)
+	},
+}
+
+var (
+	doctorJSON    bool
+	doctorTimeout time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Print results as JSON")
+	doctorCmd.Flags().
+		DurationVar(&doctorTimeout, "timeout", 10*time.Second, "Timeout for each individual check")
+}
+
+// Names of the checks others depend on.
+const (
+	doctorCheckConfig    = "config"
+	doctorCheckSecrets   = "secrets"
+	doctorCheckMinioAuth = "minio auth"
+)
+
+// doctorState carries what earlier checks produced to the checks depending on
+// them, which declare that in Needs so they never read state a timed out
+// check may still write.
+type doctorState struct {
+	cfg          *config.Config
+	secrets      map[secret.Key]string
+	mc           *minio.Client
+	bucketExists bool
+}
+
+//nolint:funlen // The list of checks is easier to follow in one place.
+func doctorChecks() []doctor.Check {
+	s := &doctorState{cfg: nil, secrets: nil, mc: nil, bucketExists: false}
+
+	return []doctor.Check{
+		{
+			Name:  "minly home",
+			Hint:  "check the directory permissions or use MINLY_HOME / --home",
+			Needs: nil,
+			Run:   checkHomeWritable,
+		},
+		{
+			Name:  doctorCheckConfig,
+			Hint:  "run 'minly init' or fix the reported value, see 'minly config --explain'",
+			Needs: nil,
+			Run:   s.checkConfig,
+		},
+		{
+			Name:  doctorCheckSecrets,
+			Hint:  "run 'minly init --reset-secrets' or set secret_backend to file if the keyring does not work",
+			Needs: []string{doctorCheckConfig},
+			Run:   s.checkSecrets,
+		},
+		{
+			Name:  "minio endpoint",
+			Hint:  "check minio_endpoint and your network or DNS settings",
+			Needs: []string{doctorCheckConfig},
+			Run:   s.checkMinioEndpoint,
+		},
+		{
+			Name:  doctorCheckMinioAuth,
+			Hint:  "check the MinIO credentials (see minio_credential_source) and their permissions on the bucket",
+			Needs: []string{doctorCheckConfig, doctorCheckSecrets},
+			Run:   s.checkMinioAuth,
+		},
+		{
+			Name:  "minio region",
+			Hint:  "set minio_region to the region the bucket was created in",
+			Needs: []string{doctorCheckMinioAuth},
+			Run:   s.checkMinioRegion,
+		},
+		{
+			Name:  "minio write",
+			Hint:  "the access key needs s3:PutObject and s3:DeleteObject on the bucket",
+			Needs: []string{doctorCheckMinioAuth},
+			Run:   s.checkMinioWrite,
+		},
+		{
+			Name:  "yourls",
+			Hint:  "check yourls_endpoint and the YOURLS signature",
+			Needs: []string{doctorCheckConfig, doctorCheckSecrets},
+			Run:   s.checkYOURLS,
+		},
+		{
+			Name:  "clipboard",
+			Hint:  "install xclip, xsel or wl-clipboard and check it can reach your display, or use 'minly upload --no-clip'",
+			Needs: nil,
+			Run:   checkClipboard,
+		},
+		{
+			Name:  "history",
+			Hint:  "inspect or move away broken files in the storage state directory",
+			Needs: nil,
+			Run:   checkHistory,
+		},
+		{
+			Name:  "last run",
+			Hint:  "check the latest log file in the logs state directory",
+			Needs: nil,
+			Run:   checkLastRun,
+		},
+	}
+}
+
+func checkHomeWritable(_ context.Context) (doctor.Status, string) {
+	dirs := []func() (string, error){
+		paths.ConfigDir,
+		func() (string, error) { return paths.StateDir() },
+		func() (string, error) { return paths.CacheDir() },
+	}
+
+	for _, dirFn := range dirs {
+		dir, err := dirFn()
+		if err != nil {
+			return doctor.StatusFail, err.Error()
+		}
+
+		var f *os.File
+		f, err = os.CreateTemp(dir, ".minly-doctor-*")
+		if err != nil {
+			return doctor.StatusFail, fmt.Sprintf("%s is not writable: %v", dir, err)
+		}
+
+		_ = f.Close()
+
+		err = os.Remove(f.Name())
+		if err != nil {
+			return doctor.StatusFail, fmt.Sprintf("failed to remove probe file in %s: %v", dir, err)
+		}
+	}
+
+	return doctor.StatusPass, "config, state and cache directories are writable"
+}
+
+func (s *doctorState) checkConfig(_ context.Context) (doctor.Status, string) {
+	c, err := config.Load(nil)
+	if err != nil {
+		return doctor.StatusFail, err.Error()
+	}
+
+	s.cfg = c
+
+	if c.FilePath() == "" {
+		return doctor.StatusWarn, "no config file found, using defaults and environment variables only"
+	}
+
+	return doctor.StatusPass, "loaded from " + c.FilePath()
+}
+
+func (s *doctorState) checkSecrets(_ context.Context) (doctor.Status, string) {
+	if s.cfg == nil {
+		return skipped("config could not be loaded")
+	}
+
+	err := setupSecretBackend(s.cfg)
+	if err != nil {
+		return doctor.StatusFail, err.Error()
+	}
+
+	secrets := make(map[secret.Key]string)
+	for _, key := range requiredSecrets(s.cfg) {
+		var value string
+		value, err = getSecret(key)
+		if err != nil {
+			return doctor.StatusFail, err.Error()
+		}
+
+		secrets[key] = value
+	}
+
+	s.secrets = secrets
+
+	return doctor.StatusPass, "all secrets readable from the " + secret.Current().Name() + " backend"
+}
+
+func (s *doctorState) checkMinioEndpoint(ctx context.Context) (doctor.Status, string) {
+	if s.cfg == nil {
+		return skipped("config could not be loaded")
+	}
+
+	host, _, err := net.SplitHostPort(s.cfg.MinioEndpoint)
+	if err != nil {
+		host = s.cfg.MinioEndpoint
+	}
+
+	var addrs []string
+	addrs, err = net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return doctor.StatusFail, fmt.Sprintf("failed to resolve %s: %v", host, err)
+	}
+
+	return doctor.StatusPass, fmt.Sprintf("%s resolves to %v", host, addrs)
+}
+
+func (s *doctorState) checkMinioAuth(ctx context.Context) (doctor.Status, string) {
+	if s.cfg == nil || s.secrets == nil {
+		return skipped("config or secrets could not be loaded")
+	}
+
+	mc, err := newMinioClient(
+		s.cfg,
+		s.secrets[secret.MinioAccessKey],
+		s.secrets[secret.MinioAccessSecret],
+	)
+	if err != nil {
+		return doctor.StatusFail, err.Error()
+	}
+
+	err = mc.Setup(s.cfg.MinioBucketName, s.cfg.MinioRegion, s.cfg.MinioLinkExpiry)
+	if err != nil {
+		return doctor.StatusFail, err.Error()
+	}
+
+	var exists bool
+	exists, err = mc.CheckBucket(ctx)
+	if err != nil {
+		return doctor.StatusFail, err.Error()
+	}
+
+	s.mc = mc
+	s.bucketExists = exists
+
+	if !exists {
+		return doctor.StatusWarn, fmt.Sprintf(
+			"authenticated, but bucket %s does not exist yet and will be created on the first upload",
+			s.cfg.MinioBucketName,
+		)
+	}
+
+	return doctor.StatusPass, "authenticated, bucket " + s.cfg.MinioBucketName + " exists"
+}
+
+func (s *doctorState) checkMinioRegion(ctx context.Context) (doctor.Status, string) {
+	if s.mc == nil || !s.bucketExists {
+		return skipped("MinIO client or bucket not available")
+	}
+
+	region, err := s.mc.BucketRegion(ctx)
+	if err != nil {
+		return doctor.StatusFail, err.Error()
+	}
+
+	expected := s.mc.Provider().Region(s.cfg.MinioEndpoint, s.cfg.MinioRegion)
+	if region != expected {
+		return doctor.StatusFail, fmt.Sprintf(
+			"bucket is in region %s, but %s is configured for provider %s",
+			region,
+			expected,
+			s.mc.Provider().Name,
+		)
+	}
+
+	return doctor.StatusPass, "bucket region matches " + region
+}
+
+func (s *doctorState) checkMinioWrite(ctx context.Context) (doctor.Status, string) {
+	if s.mc == nil || !s.bucketExists {
+		return skipped("MinIO client or bucket not available")
+	}
+
+	err := s.mc.CheckWrite(ctx)
+	if err != nil {
+		return doctor.StatusFail, err.Error()
+	}
+
+	return doctor.StatusPass, "wrote and removed a probe object"
+}
+
+func (s *doctorState) checkYOURLS(ctx context.Context) (doctor.Status, string) {
+	if s.cfg == nil || s.secrets == nil {
+		return skipped("config or secrets could not be loaded")
+	}
+
+	yc, err := newYOURLSClient(s.cfg, s.secrets[secret.YOURLSignature])
+	if err != nil {
+		return doctor.StatusFail, err.Error()
+	}
+
+	err = yc.Check(ctx)
+	if err != nil {
+		return doctor.StatusFail, err.Error()
+	}
+
+	return doctor.StatusPass, "signature accepted by " + s.cfg.YOURLSEndpoint.Host
+}
+
+func checkClipboard(ctx context.Context) (doctor.Status, string) {
+	if !clipboard.Supported() {
+		return doctor.StatusWarn, "no clipboard utility found"
+	}
+
+	// Give up shortly before the check times out, a hanging clipboard
+	// utility is reported as a warning and not as a failed check.
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-time.Until(deadline)/10))
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- clipboardRoundTrip()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return doctor.StatusWarn, err.Error()
+		}
+	case <-ctx.Done():
+		return doctor.StatusWarn, "clipboard utility did not respond in time"
+	}
+
+	return doctor.StatusPass, "clipboard can be written and read"
+}
+
+// clipboardRoundTrip writes a marker to the clipboard, reads it back and
+// restores the previous contents.
+func clipboardRoundTrip() error {
+	// Reading an empty clipboard fails with some utilities, it is
+	// restored as empty then.
+	original, _ := clipboard.Read()
+
+	marker := fmt.Sprintf("minly doctor %d", time.Now().UnixNano())
+
+	err := clipboard.Write(marker)
+	if err != nil {
+		return err
+	}
+
+	var got string
+	got, err = clipboard.Read()
+	if err == nil && got != marker {
+		err = errors.New("clipboard returned different contents than written")
+	}
+
+	restoreErr := clipboard.Write(original)
+	if restoreErr != nil {
+		restoreErr = fmt.Errorf("failed to restore clipboard contents: %w", restoreErr)
+	}
+
+	return errors.Join(err, restoreErr)
+}
+
+func checkHistory(_ context.Context) (doctor.Status, string) {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return doctor.StatusFail, err.Error()
+	}
+
+	var loaded []storage.File
+	loaded, err = store.LoadAll()
+	if err != nil {
+		return doctor.StatusFail, err.Error()
+	}
+
+	return doctor.StatusPass, fmt.Sprintf("%d entries readable", len(loaded))
+}
+
+func checkLastRun(_ context.Context) (doctor.Status, string) {
+	lastRun, err := lastrun.Read()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return doctor.StatusPass, "no previous run recorded"
+		}
+
+		return doctor.StatusWarn, err.Error()
+	}
+
+	if lastRun.Error != "" {
+		return doctor.StatusWarn, fmt.Sprintf(
+			"last run at %s failed: %s",
+			lastRun.Timestamp.Format(time.RFC3339),
+			lastRun.Error,
+		)
+	}
+
+	return doctor.StatusPass, "last run at " + lastRun.Timestamp.Format(time.RFC3339) + " succeeded"
+}
+
+func skipped(reason string) (doctor.Status, string) {
+	return doctor.StatusWarn, "skipped: " + reason
+}
@@ -0,0 +1,374 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/devusSs/minly/internal/config"
+	"github.com/devusSs/minly/internal/fetch"
+	"github.com/devusSs/minly/internal/log"
+	"github.com/devusSs/minly/internal/minio"
+	"github.com/devusSs/minly/internal/secret"
+	"github.com/devusSs/minly/internal/storage"
+)
+
+var downloadCmd = &cobra.Command{
+	Use:   "download <id|short-url|object-key>",
+	Short: "Downloads an uploaded file by history ID, short URL or object key",
+	Long: `Downloads an uploaded file and restores its original file name.
+Short URLs are expanded via YOURLS, interrupted downloads are resumed on the next call
+and files with a recorded SHA-256 checksum are verified.`,
+	Example: `  minly download https://sho.rt/abc
+  minly download 0b5c7f4e-1f0e-4c4b-9d43-0f3c2f8a1b2e -o report.pdf
+  minly download uploads/5f1d.../notes.txt -o -`,
+	Args: cobra.ExactArgs(1),
+	PreRun: func(_ *cobra.Command, _ []string) {
+		err := log.Setup()
+		checkErr(err, "failed to setup log package")
+
+		go func() {
+			err = log.CleanOld()
+			if err != nil {
+				log.Logger().Error().Err(err).Msg("failed to clean old log files")
+			}
+		}()
+	},
+	PostRun: func(_ *cobra.Command, _ []string) {
+		err := log.Flush()
+		checkErr(err, "failed to flush log package")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+		cfg, err = config.Load(nil)
+		logErr(err, "failed to load config")
+
+		log.Logger().Info().Any("config", cfg).
+			Msg("config loaded successfully")
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		var src *downloadSource
+		src, err = resolveDownload(ctx, args[0])
+		logErr(err, "failed to resolve download")
+
+		log.Logger().Info().Str("bucket", src.bucket).Str("key", src.key).
+			Str("url", src.url).Msg("download resolved")
+
+		var path string
+		path, err = downloadObject(ctx, cmd, src, downloadOutput)
+		logErr(err, "failed to download file")
+
+		if path != "" {
+			cmd.Printf("Downloaded to %s\n", path)
+		}
+	},
+}
+
+var downloadOutput string
+
+func init() {
+	rootCmd.AddCommand(downloadCmd)
+
+	downloadCmd.Flags().
+		StringVarP(&downloadOutput, "output", "o", "", "output file or directory, - writes to stdout (default original file name)")
+}
+
+// downloadSource is either an object in a bucket, a plain URL or both.
+// The URL is used when no MinIO client can be created.
+type downloadSource struct {
+	bucket string
+	key    string
+	url    string
+	sha256 string
+}
+
+func resolveDownload(ctx context.Context, arg string) (*downloadSource, error) {
+	var err error
+	fs, err = storage.NewFileStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage file store: %w", err)
+	}
+
+	files, err = fs.LoadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load files: %w", err)
+	}
+
+	f := findHistoryFile(func(f *storage.File) bool {
+		return f.ID == arg || f.YOURLSLink == arg || f.ObjectKey == arg
+	})
+	if f != nil {
+		return historySource(f), nil
+	}
+
+	u, err := url.Parse(arg)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return &downloadSource{bucket: "", key: arg, url: "", sha256: ""}, nil
+	}
+
+	long, err := expandURL(ctx, arg)
+	if err != nil {
+		log.Logger().Warn().Err(err).Str("url", arg).Msg("failed to expand URL, downloading it directly")
+
+		long = arg
+	}
+
+	f = findHistoryFile(func(f *storage.File) bool { return f.MinioLink == long })
+	if f != nil {
+		return historySource(f), nil
+	}
+
+	return &downloadSource{bucket: "", key: "", url: long, sha256: ""}, nil
+}
+
+func findHistoryFile(match func(f *storage.File) bool) *storage.File {
+	for i := range files {
+		if match(&files[i]) {
+			return &files[i]
+		}
+	}
+
+	return nil
+}
+
+func historySource(f *storage.File) *downloadSource {
+	return &downloadSource{bucket: f.Bucket, key: f.ObjectKey, url: f.MinioLink, sha256: f.SHA256}
+}
+
+func expandURL(ctx context.Context, short string) (string, error) {
+	err := setupSecretBackend(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to setup secret backend: %w", err)
+	}
+
+	var signature string
+	signature, err = getSecret(secret.YOURLSignature)
+	if err != nil {
+		return "", fmt.Errorf("failed to get YOURLS signature: %w", err)
+	}
+
+	yc, err := newYOURLSClient(cfg, signature)
+	if err != nil {
+		return "", err
+	}
+
+	var long string
+	long, err = yc.Expand(ctx, short)
+	if err != nil {
+		return "", fmt.Errorf("failed to expand short URL: %w", err)
+	}
+
+	log.Logger().Info().Str("short_url", short).Str("long_url", long).Msg("short URL expanded")
+
+	return long, nil
+}
+
+// downloadObject downloads src to output and returns the written path,
+// which is empty when writing to stdout.
+func downloadObject(ctx context.Context, cmd *cobra.Command, src *downloadSource, output string) (string, error) {
+	if src.key == "" {
+		return downloadURL(ctx, cmd, src, output)
+	}
+
+	c := cfg
+	if src.bucket != "" && src.bucket != cfg.MinioBucketName {
+		var err error
+		c, err = config.Load(map[string]any{"minio_bucket_name": src.bucket})
+		if err != nil {
+			return "", fmt.Errorf("failed to load config for bucket %s: %w", src.bucket, err)
+		}
+	}
+
+	mc, err := newBucketClient(c)
+	if err != nil {
+		if src.url == "" {
+			return "", err
+		}
+
+		log.Logger().Warn().Err(err).Msg("failed to setup MinIO client, downloading via the recorded link")
+
+		return downloadURL(ctx, cmd, src, output)
+	}
+
+	var info *minio.ObjectInfo
+	info, err = mc.Stat(ctx, src.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to get object info: %w", err)
+	}
+
+	name := info.Filename
+	if name == "" {
+		name = path.Base(src.key)
+	}
+
+	if output == "-" {
+		var r io.ReadCloser
+		r, err = mc.Open(ctx, src.key)
+		if err != nil {
+			return "", fmt.Errorf("failed to open object: %w", err)
+		}
+		defer r.Close()
+
+		return "", streamDownload(cmd.OutOrStdout(), r, src.sha256)
+	}
+
+	var dst string
+	dst, err = downloadPath(output, name)
+	if err != nil {
+		return "", err
+	}
+
+	err = mc.Download(ctx, src.key, dst)
+	if err != nil {
+		return "", fmt.Errorf("failed to download object: %w", err)
+	}
+
+	return dst, verifyDownload(dst, src.sha256)
+}
+
+func downloadURL(ctx context.Context, cmd *cobra.Command, src *downloadSource, output string) (string, error) {
+	client, err := newMinioHTTPClient(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	if output == "-" {
+		h := sha256.New()
+
+		_, err = fetch.Stream(ctx, client, src.url, io.MultiWriter(cmd.OutOrStdout(), h))
+		if err != nil {
+			return "", fmt.Errorf("failed to download URL: %w", err)
+		}
+
+		return "", checkSum(h, src.sha256)
+	}
+
+	// The name is only known from the response headers, download to a
+	// name derived from the URL first so an interrupted download resumes.
+	var dst string
+	dst, err = downloadPath(output, urlFilename(src.url))
+	if err != nil {
+		return "", err
+	}
+
+	var header http.Header
+	header, err = fetch.File(ctx, client, src.url, dst)
+	if err != nil {
+		return "", fmt.Errorf("failed to download URL: %w", err)
+	}
+
+	// Only rename when the output path was not given explicitly.
+	name := minio.HeaderFilename(header)
+	if dst != output && name != "" && filepath.IsLocal(name) && filepath.Base(name) == name {
+		named := filepath.Join(filepath.Dir(dst), name)
+
+		err = os.Rename(dst, named)
+		if err != nil {
+			return "", fmt.Errorf("failed to restore file name: %w", err)
+		}
+
+		dst = named
+	}
+
+	return dst, verifyDownload(dst, src.sha256)
+}
+
+// downloadPath returns where to write a file with the given name. An
+// existing directory as output keeps the name, an empty output uses it
+// in the working directory.
+func downloadPath(output string, name string) (string, error) {
+	// Names come from object metadata, never write outside of the directory.
+	name = filepath.Base(filepath.FromSlash(name))
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("unsafe file name %q, pass -o to choose one", name)
+	}
+
+	if output == "" {
+		return name, nil
+	}
+
+	info, err := os.Stat(output)
+	if err == nil && info.IsDir() {
+		return filepath.Join(output, name), nil
+	}
+
+	return output, nil
+}
+
+func urlFilename(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || path.Base(u.Path) == "/" || path.Base(u.Path) == "." {
+		return "download"
+	}
+
+	return path.Base(u.Path)
+}
+
+func streamDownload(w io.Writer, r io.Reader, want string) error {
+	h := sha256.New()
+
+	_, err := io.Copy(io.MultiWriter(w, h), r)
+	if err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+
+	return checkSum(h, want)
+}
+
+// verifyDownload removes the downloaded file if it does not match the
+// recorded checksum.
+func verifyDownload(path string, want string) error {
+	if want == "" {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open downloaded file: %w", err)
+	}
+
+	h := sha256.New()
+
+	_, err = io.Copy(h, f)
+	_ = f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to hash downloaded file: %w", err)
+	}
+
+	err = checkSum(h, want)
+	if err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+
+	log.Logger().Info().Str("path", path).Str("sha256", want).Msg("checksum verified")
+
+	return nil
+}
+
+func checkSum(h hash.Hash, want string) error {
+	if want == "" {
+		return nil
+	}
+
+	got := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("checksum mismatch, expected sha256 %s but got %s", want, got)
+	}
+
+	return nil
+}
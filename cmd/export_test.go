@@ -0,0 +1,11 @@
+package cmd
+
+//nolint:gochecknoglobals // Exported for tests only.
+var (
+	SummarizeUsage = summarizeUsage
+	ParseLabels    = parseLabels
+	UploadLabels   = uploadLabels
+	UserTagFlags   = userTagFlags
+)
+
+type UsageGroup = usageGroup
@@ -13,6 +13,7 @@ import (
 
 	"github.com/devusSs/minly/internal/config"
 	"github.com/devusSs/minly/internal/log"
+	"github.com/devusSs/minly/internal/minio"
 	"github.com/devusSs/minly/internal/storage"
 )
 
@@ -35,8 +36,8 @@ var filesCmd = &cobra.Command{
 			}
 		}()
 
-		cfg, err = config.Read()
-		logErr(err, "failed to read configuration")
+		cfg, err = config.Load(nil)
+		logErr(err, "failed to load configuration")
 
 		fs, err = storage.NewFileStore()
 		logErr(err, "failed to create file store")
@@ -57,13 +58,38 @@ var filesCmd = &cobra.Command{
 		checkErr(err, "failed to flush log package")
 	},
 	Run: func(_ *cobra.Command, _ []string) {
-		err := printFilesAsTable()
+		want, err := parseLabels("tag", filesTags)
+		logErr(err, "invalid --tag flag")
+
+		files = filterFilesByTags(files, want)
+
+		err = printFilesAsTable()
 		logErr(err, "failed to print files as table")
 	},
 }
 
+var filesTags []string
+
 func init() {
 	rootCmd.AddCommand(filesCmd)
+
+	filesCmd.Flags().
+		StringArrayVar(&filesTags, "tag", nil, "only list files with this tag as key=value, can be repeated")
+}
+
+func filterFilesByTags(files []storage.File, want map[string]string) []storage.File {
+	if len(want) == 0 {
+		return files
+	}
+
+	var result []storage.File
+	for _, f := range files {
+		if minio.MatchTags(f.Tags, want) {
+			result = append(result, f)
+		}
+	}
+
+	return result
 }
 
 func printFilesAsTable() error {
@@ -76,7 +102,7 @@ func printFilesAsTable() error {
 	}
 
 	table := tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{"ID", "Timestamp", "Minio Key", "Minio Link Expires", "YOURLS Key"})
+	table.Header([]string{"ID", "Timestamp", "Minio Key", "Minio Link Expires", "YOURLS Key", "Tags"})
 
 	for _, f := range files {
 		ts := f.Timestamp.Format(time.RFC3339)
@@ -93,10 +119,18 @@ func printFilesAsTable() error {
 		}
 
 		minioKey := strings.TrimPrefix(minURL.Path, "/")
+		if f.ObjectKey != "" {
+			minioKey = f.Bucket + "/" + f.ObjectKey
+		}
 		yourlsKey := strings.TrimPrefix(yourlsURL.Path, "/")
 
+		expires := "never"
+		if !f.Permanent {
+			expires = f.MinioLinkExpires.Format(time.RFC3339)
+		}
+
 		err = table.Append(
-			[]string{f.ID, ts, minioKey, f.MinioLinkExpires.Format(time.RFC3339), yourlsKey},
+			[]string{f.ID, ts, minioKey, expires, yourlsKey, formatTags(f.Tags)},
 		)
 		if err != nil {
 			return fmt.Errorf("failed to append row to table: %w", err)
@@ -79,17 +79,29 @@ var initCmd = &cobra.Command{
 			log.Logger().Info().Msg("config initialized from input")
 		}
 
-		err = checkOrSetSecret(secret.MinioAccessKey, initReSetSecrets)
-		logErr(err, "failed to check or set MinIO access key")
+		err = setupSecretBackend(cfg)
+		logErr(err, "failed to setup secret backend")
 
-		log.Logger().Info().Msg("MinIO access key set")
+		var provided map[secret.Key]string
+		provided, err = providedSecrets(cfg)
+		logErr(err, "failed to read provided secrets")
 
-		err = checkOrSetSecret(secret.MinioAccessSecret, initReSetSecrets)
-		logErr(err, "failed to check or set MinIO access secret")
+		if minioNeedsKeys(cfg) {
+			err = checkOrSetSecret(secret.MinioAccessKey, provided, initReSetSecrets)
+			logErr(err, "failed to check or set MinIO access key")
 
-		log.Logger().Info().Msg("MinIO access secret set")
+			log.Logger().Info().Msg("MinIO access key set")
 
-		err = checkOrSetSecret(secret.YOURLSignature, initReSetSecrets)
+			err = checkOrSetSecret(secret.MinioAccessSecret, provided, initReSetSecrets)
+			logErr(err, "failed to check or set MinIO access secret")
+
+			log.Logger().Info().Msg("MinIO access secret set")
+		} else {
+			log.Logger().Info().Str("source", cfg.MinioCredentialSource).
+				Msg("MinIO credential source does not use stored keys, skipping MinIO secrets")
+		}
+
+		err = checkOrSetSecret(secret.YOURLSignature, provided, initReSetSecrets)
 		logErr(err, "failed to check or set YOURLS signature")
 
 		log.Logger().Info().Msg("YOURLS signature set")
@@ -111,6 +123,10 @@ var (
 	initEnvFilePath  string
 	initOverwrite    bool
 	initReSetSecrets bool
+
+	initSecretsFromEnv bool
+	initSecretFile     string
+	initSecretStdin    string
 )
 
 func init() {
@@ -127,7 +143,15 @@ func init() {
 	initCmd.Flags().
 		BoolVar(&initReSetSecrets, "reset-secrets", false, "Re-set secrets on keychain")
 
+	initCmd.Flags().
+		BoolVar(&initSecretsFromEnv, "secrets-from-env", false, "Read secrets from MINLY_MINIO_ACCESS_KEY, MINLY_MINIO_ACCESS_SECRET and MINLY_YOURLS_SIGNATURE")
+	initCmd.Flags().
+		StringVar(&initSecretFile, "secret-file", "", "Read secrets from a JSON or dotenv file")
+	initCmd.Flags().
+		StringVar(&initSecretStdin, "secret-stdin", "", "Read the value for the given secret key from stdin")
+
 	initCmd.MarkFlagsMutuallyExclusive("file", "env")
+	initCmd.MarkFlagsMutuallyExclusive("secrets-from-env", "secret-file")
 	initCmd.MarkFlagsRequiredTogether("file", "file-path")
 }
 
@@ -138,7 +162,61 @@ func logErr(err error, msg string) {
 	}
 }
 
-func checkOrSetSecret(key secret.Key, reset bool) error {
+func providedSecrets(c *config.Config) (map[secret.Key]string, error) {
+	provided := make(map[secret.Key]string)
+
+	var err error
+
+	switch {
+	case initSecretsFromEnv:
+		provided, err = secret.FromEnv(requiredSecrets(c)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secrets from environment: %w", err)
+		}
+
+	case initSecretFile != "":
+		provided, err = secret.FromFile(initSecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secrets from file: %w", err)
+		}
+	}
+
+	if initSecretStdin != "" {
+		var key secret.Key
+		key, err = secret.ParseKey(initSecretStdin)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --secret-stdin key: %w", err)
+		}
+
+		var value string
+		value, err = secret.FromReader(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret %s from stdin: %w", key, err)
+		}
+
+		provided[key] = value
+	}
+
+	return provided, nil
+}
+
+func checkOrSetSecret(key secret.Key, provided map[secret.Key]string, reset bool) error {
+	value, ok := provided[key]
+
+	// Read-only backends like env can only be checked, there is nothing to set.
+	if !secret.Writable() {
+		return checkReadOnlySecret(key, value, ok)
+	}
+
+	if ok {
+		err := secret.Save(key, value)
+		if err != nil {
+			return fmt.Errorf("failed to save secret %s: %w", key, err)
+		}
+
+		return nil
+	}
+
 	exists, err := secret.Exists(key)
 	if err != nil {
 		return fmt.Errorf("failed to check secret %s: %w", key, err)
@@ -148,7 +226,11 @@ func checkOrSetSecret(key secret.Key, reset bool) error {
 		var input string
 		input, err = secret.GetInput(fmt.Sprintf("Enter a value for %s", key))
 		if err != nil {
-			return fmt.Errorf("failed to get input for secret %s: %w", key, err)
+			return fmt.Errorf(
+				"failed to get input for secret %s (use --secrets-from-env, --secret-file or --secret-stdin when not running in a terminal): %w",
+				key,
+				err,
+			)
 		}
 
 		err = secret.Save(key, input)
@@ -161,3 +243,41 @@ func checkOrSetSecret(key secret.Key, reset bool) error {
 
 	return nil
 }
+
+// checkReadOnlySecret checks that key is set in a read-only backend and,
+// if a value was provided, that it matches.
+func checkReadOnlySecret(key secret.Key, value string, provided bool) error {
+	exists, err := secret.Exists(key)
+	if err != nil {
+		return fmt.Errorf("failed to check secret %s: %w", key, err)
+	}
+
+	if !exists {
+		return fmt.Errorf(
+			"secret %s is not set in the read-only %s backend (for env set %s)",
+			key,
+			secret.Current().Name(),
+			key.EnvName(),
+		)
+	}
+
+	if !provided {
+		return nil
+	}
+
+	var current string
+	current, err = secret.Load(key)
+	if err != nil {
+		return fmt.Errorf("failed to load secret %s: %w", key, err)
+	}
+
+	if current != value {
+		return fmt.Errorf(
+			"provided secret %s does not match the read-only %s backend, which cannot be changed by minly",
+			key,
+			secret.Current().Name(),
+		)
+	}
+
+	return nil
+}
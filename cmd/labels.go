@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/devusSs/minly/internal/config"
+	"github.com/devusSs/minly/internal/log"
+	"github.com/devusSs/minly/internal/minio"
+	"github.com/devusSs/minly/internal/version"
+)
+
+// Labels minly adds to every upload, they cannot be set with --tag or --meta.
+const (
+	labelVersion      = "minly-version"
+	labelProject      = "minly-project"
+	labelUploaderHost = "uploader-host"
+	labelOriginalName = "original-name"
+)
+
+// S3 allows at most this many tags per object.
+const maxObjectTags = 10
+
+// parseLabels parses repeated key=value flag values.
+func parseLabels(flag string, values []string) (map[string]string, error) {
+	labels := make(map[string]string, len(values))
+
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --%s %q, must be key=value", flag, v)
+		}
+
+		if _, exists := labels[key]; exists {
+			return nil, fmt.Errorf("duplicate --%s key %s", flag, key)
+		}
+
+		labels[key] = value
+	}
+
+	return labels, nil
+}
+
+// autoLabels returns the labels minly adds to an upload of name.
+func autoLabels(c *config.Config, name string) map[string]string {
+	labels := map[string]string{
+		labelVersion:      version.GetBuild().Version,
+		labelOriginalName: name,
+	}
+
+	if c.ProjectName != "" {
+		labels[labelProject] = c.ProjectName
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		log.Logger().Warn().Err(err).Msg("failed to get hostname, not adding uploader-host label")
+	} else {
+		labels[labelUploaderHost] = host
+	}
+
+	return labels
+}
+
+// uploadLabels sets the automatic labels and the --tag and --meta flags
+// for the uploads of mc. The automatic labels are always stored as
+// metadata and, if the provider supports tagging, also as tags. The
+// returned tags are recorded in the history either way.
+func uploadLabels(
+	c *config.Config,
+	mc *minio.Client,
+	name string,
+	tagFlags []string,
+	metaFlags []string,
+) (map[string]string, error) {
+	userTags, err := parseLabels("tag", tagFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	var userMeta map[string]string
+	userMeta, err = parseLabels("meta", metaFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	auto := autoLabels(c, name)
+
+	tags := make(map[string]string, len(auto)+len(userTags))
+	metadata := make(map[string]string, len(auto)+len(userMeta))
+
+	for k, v := range auto {
+		tags[k] = minio.TagValue(v)
+		// Metadata has to be ASCII, e.g. file names are escaped.
+		metadata[k] = url.PathEscape(v)
+	}
+
+	for k, v := range userTags {
+		if _, reserved := auto[k]; reserved {
+			return nil, fmt.Errorf("tag %s is set by minly", k)
+		}
+
+		tags[k] = v
+	}
+
+	for k, v := range userMeta {
+		if _, reserved := auto[k]; reserved {
+			return nil, fmt.Errorf("metadata %s is set by minly", k)
+		}
+
+		metadata[k] = v
+	}
+
+	if len(tags) > maxObjectTags {
+		return nil, fmt.Errorf(
+			"too many tags, objects can have %d tags and minly adds %d itself",
+			maxObjectTags,
+			len(auto),
+		)
+	}
+
+	objectTags := tags
+	if !mc.Provider().Supports(minio.FeatureTagging) {
+		if len(userTags) > 0 {
+			return nil, fmt.Errorf("cannot use --tag: %w", mc.Provider().Require(minio.FeatureTagging))
+		}
+
+		objectTags = nil
+	}
+
+	err = mc.SetLabels(objectTags, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set labels: %w", err)
+	}
+
+	return tags, nil
+}
+
+// formatTags returns the tags as sorted key=value pairs for tables.
+func formatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for _, k := range sortedKeys(tags) {
+		pairs = append(pairs, k+"="+tags[k])
+	}
+
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,212 @@
+package cmd_test
+
+import (
+	"maps"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/devusSs/minly/cmd"
+	"github.com/devusSs/minly/internal/config"
+	"github.com/devusSs/minly/internal/minio"
+	"github.com/devusSs/minly/internal/version"
+)
+
+func TestParseLabels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		values  []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "no values", values: nil, want: map[string]string{}, wantErr: false},
+		{
+			name:    "key and value",
+			values:  []string{"team=infra", " env =prod"},
+			want:    map[string]string{"team": "infra", "env": "prod"},
+			wantErr: false,
+		},
+		{name: "value with equals sign", values: []string{"q=a=b"}, want: map[string]string{"q": "a=b"}, wantErr: false},
+		{name: "empty value", values: []string{"draft="}, want: map[string]string{"draft": ""}, wantErr: false},
+		{name: "missing equals sign", values: []string{"team"}, want: nil, wantErr: true},
+		{name: "empty key", values: []string{" =infra"}, want: nil, wantErr: true},
+		{name: "duplicate key", values: []string{"team=a", "team=b"}, want: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := cmd.ParseLabels("tag", tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("ParseLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUploadLabels(t *testing.T) {
+	t.Parallel()
+
+	host, err := os.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const name = "Überblick (1).pdf"
+
+	// The automatic labels of an upload of name for the project "demo".
+	auto := map[string]string{
+		"minly-version": minio.TagValue(version.GetBuild().Version),
+		"minly-project": "demo",
+		"uploader-host": minio.TagValue(host),
+		"original-name": minio.TagValue(name),
+	}
+
+	tests := []struct {
+		name      string
+		provider  string
+		tagFlags  []string
+		metaFlags []string
+		// want are the tags in addition to the automatic ones.
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:      "automatic labels only",
+			provider:  minio.ProviderMinIO,
+			tagFlags:  nil,
+			metaFlags: nil,
+			want:      map[string]string{},
+			wantErr:   false,
+		},
+		{
+			name:      "user tags and metadata",
+			provider:  minio.ProviderMinIO,
+			tagFlags:  []string{"team=infra"},
+			metaFlags: []string{"ticket=OPS-1"},
+			want:      map[string]string{"team": "infra"},
+			wantErr:   false,
+		},
+		{
+			name:      "reserved tag",
+			provider:  minio.ProviderMinIO,
+			tagFlags:  []string{"original-name=x"},
+			metaFlags: nil,
+			want:      nil,
+			wantErr:   true,
+		},
+		{
+			name:      "reserved metadata",
+			provider:  minio.ProviderMinIO,
+			tagFlags:  nil,
+			metaFlags: []string{"minly-version=x"},
+			want:      nil,
+			wantErr:   true,
+		},
+		{
+			name:      "too many tags",
+			provider:  minio.ProviderMinIO,
+			tagFlags:  []string{"a=1", "b=2", "c=3", "d=4", "e=5", "f=6", "g=7"},
+			metaFlags: nil,
+			want:      nil,
+			wantErr:   true,
+		},
+		{
+			name:      "invalid metadata value",
+			provider:  minio.ProviderMinIO,
+			tagFlags:  nil,
+			metaFlags: []string{"owner=Jürgen"},
+			want:      nil,
+			wantErr:   true,
+		},
+		{
+			name:      "provider without tagging records labels",
+			provider:  minio.ProviderR2,
+			tagFlags:  nil,
+			metaFlags: []string{"ticket=OPS-1"},
+			want:      map[string]string{},
+			wantErr:   false,
+		},
+		{
+			name:      "provider without tagging rejects tags",
+			provider:  minio.ProviderR2,
+			tagFlags:  []string{"team=infra"},
+			metaFlags: nil,
+			want:      nil,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := config.Default()
+			c.ProjectName = "demo"
+
+			got, err := cmd.UploadLabels(c, newLabelClient(t, tt.provider), name, tt.tagFlags, tt.metaFlags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UploadLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			want := maps.Clone(auto)
+			maps.Copy(want, tt.want)
+
+			if !maps.Equal(got, want) {
+				t.Errorf("UploadLabels() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// newLabelClient creates a client of provider that is set up but never
+// contacts a server.
+func newLabelClient(t *testing.T, provider string) *minio.Client {
+	t.Helper()
+
+	p, err := minio.NewProvider(provider, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := minio.NewClient(minio.Options{
+		Endpoint: "localhost:9000",
+		Credentials: minio.Credentials{
+			Source:               minio.CredentialsStatic,
+			AccessKey:            "access",
+			AccessSecret:         "secret",
+			File:                 "",
+			Profile:              "",
+			STSEndpoint:          "",
+			RoleARN:              "",
+			RoleSessionName:      "",
+			WebIdentityTokenFile: "",
+			Duration:             0,
+		},
+		UseSSL:    false,
+		Region:    "us-east-1",
+		Provider:  p,
+		Transport: nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Setup("minly", "us-east-1", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/devusSs/minly/internal/clipboard"
+	"github.com/devusSs/minly/internal/config"
+	"github.com/devusSs/minly/internal/highlight"
+	"github.com/devusSs/minly/internal/log"
+	"github.com/devusSs/minly/internal/minio"
+	"github.com/devusSs/minly/internal/storage"
+	"github.com/devusSs/minly/internal/yourls"
+)
+
+var pasteCmd = &cobra.Command{
+	Use:   "paste [text]",
+	Short: "Uploads text from stdin, the clipboard or an argument and shortens the URL",
+	Example: `  go test ./... 2>&1 | minly paste
+  minly paste --from-clipboard --lang go --html
+  minly paste "the build is broken again"`,
+	Args: cobra.MaximumNArgs(1),
+	PreRun: func(_ *cobra.Command, _ []string) {
+		if !pasteTest {
+			err := log.Setup()
+			checkErr(err, "failed to setup log package")
+
+			go func() {
+				err = log.CleanOld()
+				if err != nil {
+					log.Logger().Error().Err(err).Msg("failed to clean old log files")
+				}
+			}()
+		}
+	},
+	PostRun: func(_ *cobra.Command, _ []string) {
+		if !pasteTest {
+			err := log.Flush()
+			checkErr(err, "failed to flush log package")
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if pasteTest {
+			log.Suppress()
+			defer log.Enable()
+		}
+
+		text, err := readPasteText(args, pasteFromClipboard)
+		logErr(err, "failed to read text")
+
+		log.Logger().Info().Int("size", len(text)).Msg("text to paste received")
+
+		var lang string
+		if pasteLang != "" {
+			lang, err = highlight.Language(pasteLang)
+			logErr(err, "invalid --lang flag")
+		}
+
+		overrides, err := flagOverrides(cmd, pasteConfigFlags)
+		logErr(err, "failed to read config flags")
+
+		if pastePublic {
+			overrides["minio_link_mode"] = "public"
+		}
+
+		if pastePrivate {
+			overrides["minio_link_mode"] = "presigned"
+		}
+
+		cfg, err = config.Load(overrides)
+		logErr(err, "failed to load config")
+
+		log.Logger().Info().Any("config", cfg).
+			Msg("config loaded successfully")
+
+		d := newUploadPolicy(cfg).CheckData(pasteName, []byte(text))
+		if !d.Allowed() {
+			violations := make([]string, 0, len(d.Violations))
+			for _, v := range d.Violations {
+				violations = append(violations, v.String())
+			}
+
+			log.Logger().Warn().Int64("size", d.Size).Str("mime", d.MIME).
+				Strs("violations", violations).Msg("upload policy violated")
+
+			err = overridePolicy("the text", violations, pasteForce)
+			logErr(err, "upload blocked by policy")
+		}
+
+		var mc *minio.Client
+		var yc *yourls.Client
+		mc, yc, err = newUploadClients(cfg)
+		logErr(err, "failed to setup clients")
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		var raw, obj *minio.Object
+		raw, obj, err = uploadPaste(ctx, mc, text, lang, pasteHTML)
+		logErr(err, "failed to upload text to MinIO")
+
+		var shortURL string
+		shortURL, err = shareObject(ctx, mc, yc, obj, pasteNoClip)
+		logErr(err, "failed to share pasted text")
+
+		fs, err = storage.NewFileStore()
+		logErr(err, "failed to create storage file store")
+
+		file := newHistoryFile(mc, obj, shortURL)
+		file.Language = lang
+		if obj != raw {
+			file.OriginalKey = raw.Key
+		}
+
+		err = fs.Save(file)
+		logErr(err, "failed to save file metadata to storage")
+
+		log.Logger().Info().Msg("file metadata saved to storage successfully")
+	},
+}
+
+var (
+	pasteTest          bool
+	pasteNoClip        bool
+	pasteFromClipboard bool
+	pasteLang          string
+	pasteHTML          bool
+	pasteBucket        string
+	pasteExpiry        time.Duration
+	pastePrefix        string
+	pastePublic        bool
+	pastePrivate       bool
+	pasteForce         bool
+)
+
+var pasteConfigFlags = map[string]string{
+	"bucket": "minio_bucket_name",
+	"expiry": "minio_link_expiry",
+	"prefix": "minio_object_prefix",
+}
+
+const (
+	pasteName        = "paste.txt"
+	pasteHTMLName    = "paste.html"
+	pasteContentType = "text/plain; charset=utf-8"
+	pasteHTMLType    = "text/html; charset=utf-8"
+)
+
+func init() {
+	rootCmd.AddCommand(pasteCmd)
+
+	pasteCmd.Flags().
+		BoolVar(&pasteTest, "test", false, "run paste command in test mode (no logs)")
+	pasteCmd.Flags().
+		BoolVar(&pasteNoClip, "no-clip", false, "do not write short URL to clipboard")
+	pasteCmd.Flags().
+		BoolVar(&pasteFromClipboard, "from-clipboard", false, "paste the current clipboard contents")
+	pasteCmd.Flags().
+		StringVar(&pasteLang, "lang", "", "language of the text, e.g. go, python or console")
+	pasteCmd.Flags().
+		BoolVar(&pasteHTML, "html", false, "also upload a syntax-highlighted HTML version and link to it")
+	pasteCmd.Flags().
+		StringVar(&pasteBucket, "bucket", "", "override the MinIO bucket for this paste")
+	pasteCmd.Flags().
+		DurationVar(&pasteExpiry, "expiry", 0, "override the MinIO link expiry for this paste")
+	pasteCmd.Flags().
+		StringVar(&pastePrefix, "prefix", "", "override the MinIO object prefix for this paste")
+	pasteCmd.Flags().
+		BoolVar(&pastePublic, "public", false, "upload to the public prefix and create a permanent link")
+	pasteCmd.Flags().
+		BoolVar(&pastePrivate, "private", false, "create an expiring presigned link even if public links are configured")
+	pasteCmd.Flags().
+		BoolVar(&pasteForce, "force", false, "upload despite upload policy violations after confirmation")
+
+	pasteCmd.MarkFlagsMutuallyExclusive("public", "expiry")
+	pasteCmd.MarkFlagsMutuallyExclusive("public", "private")
+}
+
+func readPasteText(args []string, fromClipboard bool) (string, error) {
+	var text string
+
+	switch {
+	case fromClipboard && len(args) > 0:
+		return "", errors.New("cannot paste an argument and the clipboard at the same time")
+	case fromClipboard:
+		var err error
+		text, err = clipboard.Read()
+		if err != nil {
+			return "", fmt.Errorf("failed to read clipboard: %w", err)
+		}
+	case len(args) > 0:
+		text = args[0]
+	case term.IsTerminal(int(os.Stdin.Fd())):
+		return "", errors.New("no text given, pipe it to stdin, pass it as argument or use --from-clipboard")
+	default:
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+
+		text = string(b)
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("text cannot be empty")
+	}
+
+	return text, nil
+}
+
+// uploadPaste uploads the raw text and, if wanted, its HTML rendering.
+// The second object is the one to link to.
+func uploadPaste(
+	ctx context.Context,
+	mc *minio.Client,
+	text string,
+	lang string,
+	withHTML bool,
+) (*minio.Object, *minio.Object, error) {
+	var metadata map[string]string
+	if lang != "" {
+		metadata = map[string]string{"Language": lang}
+	}
+
+	raw, err := mc.UploadData(ctx, []byte(text), pasteName, pasteContentType, metadata)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to upload text: %w", err)
+	}
+
+	log.Logger().Info().Str("object_key", raw.Key).Str("language", lang).Msg("text uploaded")
+
+	if !withHTML {
+		return raw, raw, nil
+	}
+
+	var page []byte
+	page, err = highlight.HTML(text, lang, "minly paste")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render HTML: %w", err)
+	}
+
+	var html *minio.Object
+	html, err = mc.UploadData(ctx, page, pasteHTMLName, pasteHTMLType, metadata)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to upload HTML: %w", err)
+	}
+
+	log.Logger().Info().Str("object_key", html.Key).Msg("syntax-highlighted HTML uploaded")
+
+	return raw, html, nil
+}
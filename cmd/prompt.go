@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+func confirm(prompt string) (bool, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, errors.New("stdin is not a readable terminal, cannot ask for confirmation")
+	}
+
+	_, err := fmt.Fprint(os.Stdout, prompt+" [y/N]: ")
+	if err != nil {
+		return false, fmt.Errorf("failed to write prompt: %w", err)
+	}
+
+	var answer string
+	answer, err = bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read from stdin: %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,356 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/devusSs/minly/internal/config"
+	"github.com/devusSs/minly/internal/log"
+	"github.com/devusSs/minly/internal/minio"
+	"github.com/devusSs/minly/internal/preview"
+	"github.com/devusSs/minly/internal/yourls"
+)
+
+var receiveCmd = &cobra.Command{
+	Use:   "receive",
+	Short: "Creates a presigned upload link so others can send files",
+	Long: `Creates a presigned POST policy or PUT URL that allows uploads below the receive prefix.
+Every call uses a new random directory below the prefix, use "minly receive list" to see what arrived.`,
+	PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		err := log.Setup()
+		checkErr(err, "failed to setup log package")
+
+		go func() {
+			err = log.CleanOld()
+			if err != nil {
+				log.Logger().Error().Err(err).Msg("failed to clean old log files")
+			}
+		}()
+	},
+	PersistentPostRun: func(_ *cobra.Command, _ []string) {
+		err := log.Flush()
+		checkErr(err, "failed to flush log package")
+	},
+	Run: func(cmd *cobra.Command, _ []string) {
+		err := loadReceiveConfig(cmd)
+		logErr(err, "failed to load config")
+
+		if receiveMethod != "post" && receiveMethod != "put" {
+			logErr(fmt.Errorf("unknown method %s, must be post or put", receiveMethod), "invalid --method flag")
+		}
+
+		if receiveMethod == "put" && (receiveContentType != "" || receiveForm) {
+			logErr(errors.New("PUT URLs cannot restrict the content type or be used by forms"), "use --method post")
+		}
+
+		if receiveMethod == "post" && receiveName != "" {
+			logErr(errors.New("POST uploads keep the name of the uploaded file"), "--name needs --method put")
+		}
+
+		if receiveMethod == "put" {
+			log.Logger().Warn().Msg("PUT URLs cannot limit the upload size, use --method post to enforce it")
+		}
+
+		// The form is shared like an upload, its link lives as long as the
+		// presigned upload.
+		var mc *minio.Client
+		var yc *yourls.Client
+		if receiveForm {
+			mc, yc, err = newUploadClients(cfg)
+			logErr(err, "failed to setup clients")
+
+			err = mc.SetLinkExpiry(cfg.ReceiveExpiry)
+			logErr(err, "failed to set form link expiry")
+		} else {
+			mc, err = newBucketClient(cfg)
+			logErr(err, "failed to setup MinIO client")
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		// A random directory per call keeps uploads of different senders apart.
+		inbox := receivePrefix() + uuid.NewString() + "/"
+
+		var r *minio.Receive
+		if receiveMethod == "put" {
+			name := receiveName
+			if name == "" {
+				name = "upload"
+			}
+
+			r, err = mc.PresignPut(ctx, inbox+name, cfg.ReceiveExpiry)
+		} else {
+			r, err = mc.PresignPost(ctx, minio.ReceiveOptions{
+				Prefix:      inbox,
+				MaxSize:     cfg.ReceiveMaxSizeMB * bytesPerMB,
+				ContentType: receiveContentType,
+				Expiry:      cfg.ReceiveExpiry,
+			})
+		}
+		logErr(err, "failed to create presigned upload")
+
+		log.Logger().Info().Str("method", r.Method).Str("key", r.Key).
+			Str("expires", r.Expires.Format(time.RFC3339)).Msg("presigned upload created successfully")
+
+		if !receiveForm {
+			printReceiveInstructions(cmd, r)
+			return
+		}
+
+		err = publishReceiveForm(ctx, cmd, mc, yc, r)
+		logErr(err, "failed to publish upload form")
+	},
+}
+
+var receiveListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists files that arrived below the receive prefix and downloads them",
+	Run: func(cmd *cobra.Command, _ []string) {
+		err := loadReceiveConfig(cmd)
+		logErr(err, "failed to load config")
+
+		var mc *minio.Client
+		mc, err = newBucketClient(cfg)
+		logErr(err, "failed to setup MinIO client")
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		prefix := receivePrefix()
+
+		var objects []minio.ObjectInfo
+		objects, err = mc.List(ctx, prefix)
+		logErr(err, "failed to list received files")
+
+		if len(objects) == 0 {
+			logErr(errors.New("no files found"), "nothing arrived below "+prefix)
+		}
+
+		sort.Slice(objects, func(i, j int) bool { return objects[i].LastModified.Before(objects[j].LastModified) })
+
+		err = printReceivedAsTable(prefix, objects)
+		logErr(err, "failed to print received files as table")
+
+		if receiveDownload {
+			err = downloadReceived(ctx, mc, prefix, objects, receiveDir)
+			logErr(err, "failed to download received files")
+		}
+	},
+}
+
+var (
+	receiveMethod      string
+	receiveName        string
+	receivePrefixFlag  string
+	receiveMaxSize     int64
+	receiveContentType string
+	receiveExpiry      time.Duration
+	receiveForm        bool
+	receiveNoClip      bool
+
+	receiveDownload bool
+	receiveDir      string
+)
+
+var receiveConfigFlags = map[string]string{
+	"prefix":   "receive_prefix",
+	"max-size": "receive_max_size_mb",
+	"expiry":   "receive_expiry",
+}
+
+func init() {
+	rootCmd.AddCommand(receiveCmd)
+	receiveCmd.AddCommand(receiveListCmd)
+
+	receiveCmd.PersistentFlags().
+		StringVar(&receivePrefixFlag, "prefix", "", "override the receive prefix")
+
+	receiveCmd.Flags().
+		StringVar(&receiveMethod, "method", "post", "presigned request to create, post (with limits) or put")
+	receiveCmd.Flags().
+		StringVar(&receiveName, "name", "", "object name for PUT uploads (default upload)")
+	receiveCmd.Flags().
+		Int64Var(&receiveMaxSize, "max-size", 0, "override the maximum upload size in MB")
+	receiveCmd.Flags().
+		StringVar(&receiveContentType, "content-type", "", "only allow this content type, e.g. application/zip or image/*")
+	receiveCmd.Flags().
+		DurationVar(&receiveExpiry, "expiry", 0, "override how long uploads are allowed")
+	receiveCmd.Flags().
+		BoolVar(&receiveForm, "form", false, "publish an HTML upload form and shorten its link")
+	receiveCmd.Flags().
+		BoolVar(&receiveNoClip, "no-clip", false, "do not write the short form URL to clipboard")
+
+	receiveListCmd.Flags().
+		BoolVar(&receiveDownload, "download", false, "download the listed files")
+	receiveListCmd.Flags().
+		StringVar(&receiveDir, "dir", ".", "directory to download files to")
+}
+
+func loadReceiveConfig(cmd *cobra.Command) error {
+	overrides, err := flagOverrides(cmd, receiveConfigFlags)
+	if err != nil {
+		return fmt.Errorf("failed to read config flags: %w", err)
+	}
+
+	cfg, err = config.Load(overrides)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	log.Logger().Info().Any("config", cfg).
+		Msg("config loaded successfully")
+
+	return nil
+}
+
+func receivePrefix() string {
+	if strings.HasSuffix(cfg.ReceivePrefix, "/") {
+		return cfg.ReceivePrefix
+	}
+
+	return cfg.ReceivePrefix + "/"
+}
+
+func printReceiveInstructions(cmd *cobra.Command, r *minio.Receive) {
+	cmd.Printf("Uploads are allowed until %s.\n\n", r.Expires.Format(time.RFC3339))
+
+	if r.Method == "PUT" {
+		cmd.Println("Upload a file with:")
+		cmd.Printf("  curl -T <file> '%s'\n", r.URL.String())
+		return
+	}
+
+	names := make([]string, 0, len(r.Fields))
+	for name := range r.Fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	cmd.Printf("Upload a file up to %s with:\n", preview.FormatSize(cfg.ReceiveMaxSizeMB*bytesPerMB))
+	// Without a restriction or with a wildcard like image/* the policy only
+	// fixes the start of the content type, the sender has to complete it.
+	contentType, ok := r.Fields["Content-Type"]
+	needsType := ok && (contentType == "" || strings.HasSuffix(contentType, "/"))
+
+	cmd.Print("  curl")
+	for _, name := range names {
+		value := r.Fields[name]
+		if name == "Content-Type" && needsType {
+			value += "<type>"
+		}
+
+		cmd.Printf(" \\\n    -F '%s=%s'", name, value)
+	}
+	cmd.Printf(" \\\n    -F 'file=@<file>' \\\n    '%s'\n", r.URL.String())
+
+	if needsType {
+		cmd.Println("\nReplace <type> so Content-Type is the type of the file, e.g. image/png or application/pdf.")
+	}
+}
+
+func publishReceiveForm(
+	ctx context.Context,
+	cmd *cobra.Command,
+	mc *minio.Client,
+	yc *yourls.Client,
+	r *minio.Receive,
+) error {
+	page, err := preview.RenderForm(preview.Form{
+		Title:   "Send files to " + cfg.ProjectName,
+		Action:  r.URL.String(),
+		Fields:  r.Fields,
+		MaxSize: cfg.ReceiveMaxSizeMB * bytesPerMB,
+		Accept:  receiveContentType,
+		Expires: r.Expires,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render upload form: %w", err)
+	}
+
+	var obj *minio.Object
+	obj, err = mc.UploadData(ctx, page, "upload.html", "text/html; charset=utf-8", nil)
+	if err != nil {
+		return fmt.Errorf("failed to upload form: %w", err)
+	}
+
+	var shortURL string
+	shortURL, err = shareObject(ctx, mc, yc, obj, receiveNoClip)
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Upload form: %s\n", shortURL)
+
+	return nil
+}
+
+func printReceivedAsTable(prefix string, objects []minio.ObjectInfo) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Key", "Size", "Content Type", "Arrived"})
+
+	for _, o := range objects {
+		err := table.Append([]string{
+			strings.TrimPrefix(o.Key, prefix),
+			preview.FormatSize(o.Size),
+			o.ContentType,
+			o.LastModified.Format(time.RFC3339),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to append row to table: %w", err)
+		}
+	}
+
+	err := table.Render()
+	if err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+
+	return nil
+}
+
+func downloadReceived(
+	ctx context.Context,
+	mc *minio.Client,
+	prefix string,
+	objects []minio.ObjectInfo,
+	dir string,
+) error {
+	for _, o := range objects {
+		// Keys are chosen by the sender, never write outside of dir.
+		rel := strings.TrimPrefix(o.Key, prefix)
+		if !filepath.IsLocal(filepath.FromSlash(rel)) {
+			log.Logger().Warn().Str("key", o.Key).Msg("skipping file with unsafe name")
+			continue
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+
+		info, err := os.Stat(path)
+		if err == nil && info.Size() == o.Size {
+			log.Logger().Info().Str("key", o.Key).Str("path", path).Msg("file already downloaded")
+			continue
+		}
+
+		err = mc.Download(ctx, o.Key, path)
+		if err != nil {
+			return fmt.Errorf("failed to download received file: %w", err)
+		}
+
+		log.Logger().Info().Str("key", o.Key).Str("path", path).Msg("file downloaded successfully")
+	}
+
+	return nil
+}
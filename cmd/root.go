@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/devusSs/minly/internal/lastrun"
+	"github.com/devusSs/minly/internal/paths"
 	"github.com/devusSs/minly/internal/system"
 )
 
@@ -51,6 +52,15 @@ For more help or information check out the GitHub repository.`,
 	},
 }
 
+var rootHome string
+
+func init() {
+	cobra.OnInitialize(initPaths)
+
+	rootCmd.PersistentFlags().
+		StringVar(&rootHome, "home", "", "Use this directory for all minly data (overrides MINLY_HOME and XDG directories)")
+}
+
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
@@ -58,6 +68,25 @@ func Execute() {
 	}
 }
 
+func initPaths() {
+	if rootHome != "" {
+		paths.SetHome(rootHome)
+	}
+
+	// Every command runs this, a failed migration must not break them. The
+	// legacy directory is kept and the migration retried on the next run.
+	migrated, err := paths.Migrate()
+	if err != nil {
+		_, err = fmt.Fprintf(os.Stderr, "WARNING: failed to migrate legacy ~/.minly directory, keeping it: %v\n", err)
+		checkErr(err, "failed to write migration warning")
+	}
+
+	if migrated {
+		_, err = fmt.Fprintln(os.Stderr, "INFO: migrated legacy ~/.minly directory to XDG base directories")
+		checkErr(err, "failed to write migration message")
+	}
+}
+
 func checkErr(err error, msg string) {
 	if err != nil {
 		cobra.CheckErr(fmt.Sprintf("%s: %v", msg, err))
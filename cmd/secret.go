@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/devusSs/minly/internal/config"
+	"github.com/devusSs/minly/internal/log"
+	"github.com/devusSs/minly/internal/secret"
+)
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage where minly stores its secrets",
+	PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		err := log.Setup()
+		checkErr(err, "failed to setup log package")
+
+		go func() {
+			err = log.CleanOld()
+			if err != nil {
+				log.Logger().Error().Err(err).Msg("failed to clean old log files")
+			}
+		}()
+	},
+	PersistentPostRun: func(_ *cobra.Command, _ []string) {
+		err := log.Flush()
+		checkErr(err, "failed to flush log package")
+	},
+}
+
+var secretMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copy all secrets to another backend and switch the config to it",
+	Run: func(_ *cobra.Command, _ []string) {
+		if !slices.Contains(secret.Backends(), secretMigrateTo) {
+			logErr(
+				fmt.Errorf("unknown backend %s", secretMigrateTo),
+				"--to must be one of "+strings.Join(secret.Backends(), ", "),
+			)
+		}
+
+		effective, err := config.Load(nil)
+		logErr(err, "failed to load config")
+
+		// The effective config may contain env or flag overrides,
+		// only the stored config file is updated.
+		var stored *config.Config
+		stored, err = config.Read()
+		logErr(err, "failed to read config file")
+
+		var from secret.Backend
+		from, err = newSecretBackend(effective, effective.SecretBackend)
+		logErr(err, "failed to create source secret backend")
+
+		if from.Name() == secretMigrateTo {
+			logErr(errors.New("same backend"), "secrets are already stored in the "+secretMigrateTo+" backend")
+		}
+
+		var to secret.Backend
+		to, err = newSecretBackend(effective, secretMigrateTo)
+		logErr(err, "failed to create target secret backend")
+
+		if !to.Writable() {
+			logErr(errors.New("read-only backend"), "cannot migrate secrets to the read-only "+to.Name()+" backend")
+		}
+
+		if secretMigrateDeleteSource && !from.Writable() {
+			logErr(
+				errors.New("read-only backend"),
+				"cannot delete secrets from the read-only "+from.Name()+" backend, run again without --delete-source",
+			)
+		}
+
+		log.Logger().Info().Str("from", from.Name()).Str("to", to.Name()).Msg("migrating secrets")
+
+		for _, key := range secret.AllKeys() {
+			var value string
+			value, err = from.Get(key)
+			if errors.Is(err, secret.ErrNotFound) {
+				log.Logger().Warn().Str("key", string(key)).Msg("secret not found in source backend, skipping")
+				continue
+			}
+			logErr(err, "failed to read secret "+string(key))
+
+			err = to.Set(key, value)
+			logErr(err, "failed to write secret "+string(key))
+
+			log.Logger().Info().Str("key", string(key)).Msg("secret migrated")
+		}
+
+		stored.SecretBackend = secretMigrateTo
+
+		err = config.Write(stored)
+		logErr(err, "failed to write config")
+
+		log.Logger().Info().Str("backend", secretMigrateTo).Msg("config switched to new secret backend")
+
+		if !secretMigrateDeleteSource {
+			return
+		}
+
+		// The config already points to the new backend, so failed deletes
+		// are reported but do not fail the migration.
+		var errs []error
+		for _, key := range secret.AllKeys() {
+			err = from.Delete(key)
+			if err != nil && !errors.Is(err, secret.ErrNotFound) {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+
+		err = errors.Join(errs...)
+		if err != nil {
+			log.Logger().Warn().Err(err).Str("backend", from.Name()).Msg("failed to delete secrets from source backend")
+
+			_, err = fmt.Fprintf(
+				os.Stderr,
+				"WARNING: secrets were migrated but some could not be deleted from the %s backend:\n%v\n",
+				from.Name(),
+				err,
+			)
+			logErr(err, "failed to print warning")
+
+			return
+		}
+
+		log.Logger().Info().Str("backend", from.Name()).Msg("secrets deleted from source backend")
+	},
+}
+
+var (
+	secretMigrateTo           string
+	secretMigrateDeleteSource bool
+)
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+
+	secretCmd.AddCommand(secretMigrateCmd)
+
+	secretMigrateCmd.Flags().
+		StringVar(&secretMigrateTo, "to", "", "Target backend ("+strings.Join(secret.Backends(), ", ")+")")
+	secretMigrateCmd.Flags().
+		BoolVar(&secretMigrateDeleteSource, "delete-source", false, "Delete the secrets from the source backend afterwards")
+
+	_ = secretMigrateCmd.MarkFlagRequired("to")
+}
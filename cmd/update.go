@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/devusSs/minly/internal/config"
 	"github.com/devusSs/minly/internal/log"
 	"github.com/devusSs/minly/internal/update"
 	"github.com/devusSs/minly/internal/version"
@@ -34,6 +35,16 @@ var updateCmd = &cobra.Command{
 		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 		defer cancel()
 
+		c, err := config.Load(nil)
+		if err != nil {
+			// Updating must keep working with a broken or missing config,
+			// the transport then only honors the proxy environment variables.
+			log.Logger().Warn().Err(err).Msg("failed to load config, using default HTTP settings")
+			c = config.Default()
+		}
+
+		update.SetTransport(newTransport(c, nil))
+
 		log.Logger().Info().Msg("starting update process")
 
 		u, err := update.DoUpdate(ctx, version.GetBuild().Version)
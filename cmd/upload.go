@@ -2,19 +2,29 @@ package cmd
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
-	"net/url"
+	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/devusSs/minly/internal/archive"
 	"github.com/devusSs/minly/internal/clipboard"
 	"github.com/devusSs/minly/internal/config"
+	"github.com/devusSs/minly/internal/fetch"
 	"github.com/devusSs/minly/internal/log"
 	"github.com/devusSs/minly/internal/minio"
-	"github.com/devusSs/minly/internal/secret"
+	"github.com/devusSs/minly/internal/optimize"
+	"github.com/devusSs/minly/internal/policy"
+	"github.com/devusSs/minly/internal/preview"
+	"github.com/devusSs/minly/internal/sanitize"
 	"github.com/devusSs/minly/internal/storage"
 	"github.com/devusSs/minly/internal/yourls"
 )
@@ -41,7 +51,7 @@ var uploadCmd = &cobra.Command{
 			checkErr(err, "failed to flush log package")
 		}
 	},
-	Run: func(_ *cobra.Command, args []string) {
+	Run: func(cmd *cobra.Command, args []string) {
 		if uploadTest {
 			log.Suppress()
 			defer log.Enable()
@@ -59,97 +69,128 @@ var uploadCmd = &cobra.Command{
 		log.Logger().Info().Str("file_path", filePath).
 			Msg("file path argument received")
 
-		var err error
-		cfg, err = config.Read()
-		logErr(err, "failed to read config file")
+		overrides, err := flagOverrides(cmd, uploadConfigFlags)
+		logErr(err, "failed to read config flags")
 
-		log.Logger().Info().Any("config", cfg).
-			Msg("config file read successfully")
+		if uploadPublic {
+			overrides["minio_link_mode"] = "public"
+		}
 
-		var minioAccessKey string
-		minioAccessKey, err = getSecret(secret.MinioAccessKey)
-		logErr(err, "failed to get MinIO access key secret")
+		if uploadPrivate {
+			overrides["minio_link_mode"] = "presigned"
+		}
 
-		log.Logger().Info().Msg("got MinIO access key successfully")
+		cfg, err = config.Load(overrides)
+		logErr(err, "failed to load config")
 
-		var minioAccessSecret string
-		minioAccessSecret, err = getSecret(secret.MinioAccessSecret)
-		logErr(err, "failed to get MinIO access secret")
+		if cfg.FilePath() == "" {
+			log.Logger().Warn().
+				Msg("no config file found, using defaults, environment variables and flags only")
+		}
 
-		log.Logger().Info().Msg("got MinIO access secret successfully")
+		log.Logger().Info().Any("config", cfg).
+			Msg("config loaded successfully")
 
-		var yourlsSignature string
-		yourlsSignature, err = getSecret(secret.YOURLSignature)
-		logErr(err, "failed to get YOURLS signature")
+		var format archive.Format
+		var members []archive.Member
+		var stats archive.Stats
+		if uploadArchive != "" {
+			format, err = archive.ParseFormat(uploadArchive)
+			logErr(err, "invalid --archive flag")
 
-		log.Logger().Info().Msg("got YOURLS signature successfully")
+			members, stats, err = archive.Collect(filePath)
+			logErr(err, "failed to collect directory members")
 
-		var mc *minio.Client
-		mc, err = minio.NewClient(
-			cfg.MinioEndpoint,
-			minioAccessKey,
-			minioAccessSecret,
-			cfg.MinioUseSSL,
-			cfg.MinioRegion,
-		)
-		logErr(err, "failed to create MinIO client")
+			log.Logger().Info().Str("dir", filePath).Int("members", stats.Members).Int64("size", stats.Size).
+				Msg("directory members collected")
 
-		log.Logger().Info().
-			Str("minio_endpoint", cfg.MinioEndpoint).
-			Bool("minio_use_ssl", cfg.MinioUseSSL).
-			Str("minio_region", cfg.MinioRegion).
-			Msg("MinIO client created successfully")
+			err = checkArchivePolicy(cfg, filePath, members, stats, uploadForce)
+			logErr(err, "upload blocked by policy")
+		} else {
+			var info os.FileInfo
+			info, err = os.Stat(filePath)
+			logErr(err, "failed to stat file")
 
-		err = mc.Setup(cfg.MinioBucketName, cfg.MinioRegion, cfg.MinioLinkExpiry)
-		logErr(err, "failed to setup MinIO client")
+			if info.IsDir() {
+				logErr(errors.New("file path is a directory"), "pass --archive zip|tgz to upload a directory")
+			}
 
-		log.Logger().Info().
-			Str("minio_bucket_name", cfg.MinioBucketName).
-			Str("minio_region", cfg.MinioRegion).
-			Str("minio_link_expiry", cfg.MinioLinkExpiry.String()).
-			Msg("MinIO client setup successfully")
+			err = checkUploadPolicy(cfg, filePath, uploadForce)
+			logErr(err, "upload blocked by policy")
+		}
 
+		var mc *minio.Client
 		var yc *yourls.Client
-		yc, err = yourls.NewClient(cfg.YOURLSEndpoint.String(), yourlsSignature)
-		logErr(err, "failed to create YOURLS client")
+		mc, yc, err = newUploadClients(cfg)
+		logErr(err, "failed to setup clients")
 
-		log.Logger().Info().Msg("YOURLS client created successfully")
+		name := filepath.Base(filePath)
+		if uploadArchive != "" {
+			name, err = archive.Name(filePath, format)
+			logErr(err, "failed to get archive name")
+		}
+
+		var tags map[string]string
+		tags, err = uploadLabels(cfg, mc, name, uploadTags, uploadMeta)
+		logErr(err, "invalid --tag or --meta flag")
 
 		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 		defer cancel()
 
-		var presignedURL *url.URL
-		presignedURL, err = mc.UploadFile(ctx, filePath)
+		var up *uploaded
+		if uploadArchive != "" {
+			up, err = uploadDirectory(ctx, mc, filePath, format, members, uploadKeepMetadata)
+		} else {
+			up, err = uploadPrepared(ctx, cfg, mc, filePath, prepareOptions{
+				keepMetadata: uploadKeepMetadata,
+				withOriginal: uploadWithOriginal,
+				replace:      uploadReplace,
+			})
+		}
 		logErr(err, "failed to upload file to MinIO")
 
-		presignedURLExpiry := time.Now().Add(cfg.MinioLinkExpiry)
-		log.Logger().Info().
-			Str("presigned_url", presignedURL.String()).
-			Str("presigned_url_expiry", presignedURLExpiry.String()).
-			Msg("file uploaded to MinIO successfully")
+		obj := up.object
 
-		var shortURL string
-		shortURL, err = yc.Shorten(ctx, presignedURL.String())
-		logErr(err, "failed to shorten presigned URL using YOURLS")
-
-		log.Logger().Info().Str("short_url", shortURL).
-			Msg("presigned URL shortened successfully")
-
-		if !uploadNoClip {
-			err = clipboard.Write(shortURL)
-			logErr(err, "failed to write short URL to clipboard")
+		if cfg.UploadVerifyLink {
+			err = verifyLink(ctx, cfg, mc, obj)
+			logErr(err, "failed to verify link")
+		}
 
-			log.Logger().Info().Msg("short URL written to clipboard successfully")
-		} else {
-			log.Logger().Warn().Msg("short URL not written to clipboard due to --no-clip flag")
+		// With a preview page the short link points at the page, the
+		// history still records the uploaded object.
+		shared := obj
+		if uploadPreviewPage {
+			shared, err = uploadPreview(ctx, mc, up)
+			logErr(err, "failed to upload preview page")
 		}
 
+		var shortURL string
+		shortURL, err = shareObject(ctx, mc, yc, shared, uploadNoClip)
+		logErr(err, "failed to share uploaded file")
+
 		fs, err = storage.NewFileStore()
 		logErr(err, "failed to create storage file store")
 
 		log.Logger().Info().Msg("storage file store created successfully")
 
-		err = fs.Save(storage.NewFile(presignedURL.String(), presignedURLExpiry, shortURL))
+		file := newHistoryFile(mc, obj, shortURL)
+		file.Sanitized = up.sanitized
+		file.MetadataRemoved = up.removed
+		file.Tags = tags
+		if up.original != nil {
+			file.OriginalKey = up.original.Key
+		}
+
+		if shared != obj {
+			file.PreviewKey = shared.Key
+		}
+
+		if uploadArchive != "" {
+			file.ArchiveMembers = stats.Members
+			file.ArchiveSize = stats.Size
+		}
+
+		err = fs.Save(file)
 		logErr(err, "failed to save file metadata to storage")
 
 		log.Logger().Info().Msg("file metadata saved to storage successfully")
@@ -157,10 +198,46 @@ var uploadCmd = &cobra.Command{
 }
 
 var (
-	uploadTest   bool
-	uploadNoClip bool
+	uploadTest     bool
+	uploadNoClip   bool
+	uploadEndpoint string
+	uploadBucket   string
+	uploadRegion   string
+	uploadExpiry   time.Duration
+	uploadPrefix   string
+	uploadPublic   bool
+	uploadPrivate  bool
+	uploadForce    bool
+	uploadArchive  string
+
+	uploadPreviewPage bool
+	uploadVerifyLink  bool
+
+	uploadTags []string
+	uploadMeta []string
+
+	uploadKeepMetadata bool
+	uploadOptimize     bool
+	uploadMaxDimension int
+	uploadQuality      int
+	uploadReplace      bool
+	uploadWithOriginal bool
 )
 
+var uploadConfigFlags = map[string]string{
+	"endpoint": "minio_endpoint",
+	"bucket":   "minio_bucket_name",
+	"region":   "minio_region",
+	"expiry":   "minio_link_expiry",
+	"prefix":   "minio_object_prefix",
+
+	"verify-link": "upload_verify_link",
+
+	"optimize":      "image_optimize",
+	"max-dimension": "image_max_dimension",
+	"quality":       "image_quality",
+}
+
 func init() {
 	rootCmd.AddCommand(uploadCmd)
 
@@ -168,4 +245,586 @@ func init() {
 		BoolVar(&uploadTest, "test", false, "run upload command in test mode (no logs)")
 	uploadCmd.Flags().
 		BoolVar(&uploadNoClip, "no-clip", false, "do not write short URL to clipboard")
+	uploadCmd.Flags().
+		StringVar(&uploadEndpoint, "endpoint", "", "override the MinIO endpoint for this upload")
+	uploadCmd.Flags().
+		StringVar(&uploadBucket, "bucket", "", "override the MinIO bucket for this upload")
+	uploadCmd.Flags().
+		StringVar(&uploadRegion, "region", "", "override the MinIO region for this upload")
+	uploadCmd.Flags().
+		DurationVar(&uploadExpiry, "expiry", 0, "override the MinIO link expiry for this upload")
+	uploadCmd.Flags().
+		StringVar(&uploadPrefix, "prefix", "", "override the MinIO object prefix for this upload")
+	uploadCmd.Flags().
+		BoolVar(&uploadPublic, "public", false, "upload to the public prefix and create a permanent link")
+	uploadCmd.Flags().
+		BoolVar(&uploadPrivate, "private", false, "create an expiring presigned link even if public links are configured")
+
+	uploadCmd.Flags().
+		BoolVar(&uploadForce, "force", false, "upload despite upload policy violations after confirmation")
+
+	uploadCmd.Flags().
+		BoolVar(&uploadVerifyLink, "verify-link", false, "download the uploaded file through its link and compare checksums before sharing")
+
+	uploadCmd.Flags().
+		StringArrayVar(&uploadTags, "tag", nil, "add an object tag as key=value, can be repeated")
+	uploadCmd.Flags().
+		StringArrayVar(&uploadMeta, "meta", nil, "add user metadata as key=value, can be repeated")
+
+	uploadCmd.Flags().
+		BoolVar(&uploadKeepMetadata, "keep-metadata", false, "do not strip EXIF, XMP and IPTC metadata from images")
+
+	uploadCmd.Flags().
+		BoolVar(&uploadOptimize, "optimize", false, "downscale and re-encode JPEG and PNG images")
+	uploadCmd.Flags().
+		IntVar(&uploadMaxDimension, "max-dimension", 0, "override the maximum image width or height in pixels")
+	uploadCmd.Flags().
+		IntVar(&uploadQuality, "quality", 0, "override the JPEG quality (1-100)")
+	uploadCmd.Flags().
+		BoolVar(&uploadReplace, "replace", false, "replace the local image with the optimized one")
+	uploadCmd.Flags().
+		BoolVar(&uploadWithOriginal, "with-original", false, "upload the original image in addition to the optimized one")
+
+	uploadCmd.Flags().
+		StringVar(&uploadArchive, "archive", "", "upload a directory as a streamed zip or tgz archive")
+
+	uploadCmd.Flags().
+		BoolVar(&uploadPreviewPage, "preview-page", false, "link to an HTML preview page with OpenGraph tags instead of the file")
+
+	uploadCmd.MarkFlagsMutuallyExclusive("public", "expiry")
+	uploadCmd.MarkFlagsMutuallyExclusive("public", "private")
+	uploadCmd.MarkFlagsMutuallyExclusive("archive", "optimize")
+	uploadCmd.MarkFlagsMutuallyExclusive("archive", "replace")
+	uploadCmd.MarkFlagsMutuallyExclusive("archive", "with-original")
+}
+
+// shareObject shortens the link of an uploaded object and copies it to the clipboard.
+func shareObject(
+	ctx context.Context,
+	mc *minio.Client,
+	yc *yourls.Client,
+	obj *minio.Object,
+	noClip bool,
+) (string, error) {
+	if mc.Public() {
+		log.Logger().Info().
+			Str("bucket", obj.Bucket).
+			Str("object_key", obj.Key).
+			Str("public_url", obj.URL.String()).
+			Msg("file uploaded to MinIO successfully, the link does not expire")
+	} else {
+		log.Logger().Info().
+			Str("bucket", obj.Bucket).
+			Str("object_key", obj.Key).
+			Str("presigned_url", obj.URL.String()).
+			Str("presigned_url_expiry", obj.Expires.String()).
+			Msg("file uploaded to MinIO successfully")
+	}
+
+	shortURL, err := yc.Shorten(ctx, obj.URL.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to shorten MinIO URL using YOURLS: %w", err)
+	}
+
+	log.Logger().Info().Str("short_url", shortURL).
+		Msg("MinIO URL shortened successfully")
+
+	if noClip {
+		log.Logger().Warn().Msg("short URL not written to clipboard due to --no-clip flag")
+		return shortURL, nil
+	}
+
+	err = clipboard.Write(shortURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to write short URL to clipboard: %w", err)
+	}
+
+	log.Logger().Info().Msg("short URL written to clipboard successfully")
+
+	return shortURL, nil
+}
+
+func newHistoryFile(mc *minio.Client, obj *minio.Object, shortURL string) *storage.File {
+	var file *storage.File
+	if mc.Public() {
+		file = storage.NewPermanentFile(obj.Bucket, obj.Key, obj.URL.String(), shortURL)
+	} else {
+		file = storage.NewFile(obj.Bucket, obj.Key, obj.URL.String(), obj.Expires, shortURL)
+	}
+
+	file.SHA256 = obj.SHA256
+
+	return file
+}
+
+// verifyLink downloads the object through its link to make sure it works
+// before it is shared. The object is removed if it does not.
+func verifyLink(ctx context.Context, c *config.Config, mc *minio.Client, obj *minio.Object) error {
+	client, err := newMinioHTTPClient(c)
+	if err != nil {
+		return err
+	}
+
+	h := sha256.New()
+
+	_, err = fetch.Stream(ctx, client, obj.URL.String(), h)
+	if err == nil {
+		err = checkSum(h, obj.SHA256)
+	}
+
+	if err != nil {
+		rmErr := mc.RemoveObject(context.WithoutCancel(ctx), obj.Key)
+		if rmErr != nil {
+			log.Logger().Error().Err(rmErr).Str("object_key", obj.Key).Msg("failed to remove unverified object")
+		}
+
+		return fmt.Errorf("link of %s does not serve the uploaded file: %w", obj.Key, err)
+	}
+
+	log.Logger().Info().Str("object_key", obj.Key).Str("sha256", obj.SHA256).Msg("link verified successfully")
+
+	return nil
+}
+
+func newUploadPolicy(c *config.Config) policy.Policy {
+	return policy.Policy{
+		MaxSize:      c.UploadMaxSizeMB * bytesPerMB,
+		AllowedMIME:  c.UploadAllowedMIME,
+		DeniedMIME:   c.UploadDeniedMIME,
+		DeniedNames:  c.UploadDeniedNames,
+		ScanSecrets:  c.UploadScanSecrets,
+		ScanMaxBytes: 0,
+	}
+}
+
+func checkUploadPolicy(c *config.Config, filePath string, force bool) error {
+	d, err := newUploadPolicy(c).Check(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to check upload policy: %w", err)
+	}
+
+	if d.Allowed() {
+		log.Logger().Info().Str("file_path", d.Path).Int64("size", d.Size).Str("mime", d.MIME).
+			Msg("upload policy passed")
+		return nil
+	}
+
+	violations := make([]string, 0, len(d.Violations))
+	for _, v := range d.Violations {
+		violations = append(violations, v.String())
+	}
+
+	log.Logger().Warn().Str("file_path", d.Path).Int64("size", d.Size).Str("mime", d.MIME).
+		Strs("violations", violations).Msg("upload policy violated")
+
+	return overridePolicy(filePath, violations, force)
+}
+
+// checkArchivePolicy applies the upload policy to every file of an archive,
+// the size limit applies to the uncompressed size of all files together.
+func checkArchivePolicy(
+	c *config.Config,
+	dir string,
+	members []archive.Member,
+	stats archive.Stats,
+	force bool,
+) error {
+	p := newUploadPolicy(c)
+	maxSize := p.MaxSize
+	p.MaxSize = 0
+
+	var violations []string
+	for _, m := range members {
+		if !m.Info.Mode().IsRegular() {
+			continue
+		}
+
+		d, err := p.Check(m.Path)
+		if err != nil {
+			return fmt.Errorf("failed to check upload policy for %s: %w", m.Name, err)
+		}
+
+		for _, v := range d.Violations {
+			violations = append(violations, m.Name+": "+v.String())
+		}
+	}
+
+	if maxSize > 0 && stats.Size > maxSize {
+		violations = append(violations, policy.Violation{
+			Rule:   "max size",
+			Detail: fmt.Sprintf("directory has %d bytes uncompressed, the limit is %d bytes", stats.Size, maxSize),
+		}.String())
+	}
+
+	if len(violations) == 0 {
+		log.Logger().Info().Str("dir", dir).Int("members", stats.Members).Int64("size", stats.Size).
+			Msg("upload policy passed")
+		return nil
+	}
+
+	log.Logger().Warn().Str("dir", dir).Int("members", stats.Members).Int64("size", stats.Size).
+		Strs("violations", violations).Msg("upload policy violated")
+
+	return overridePolicy(dir, violations, force)
+}
+
+func overridePolicy(filePath string, violations []string, force bool) error {
+	if !force {
+		return fmt.Errorf("%s, pass --force to upload anyway", strings.Join(violations, "; "))
+	}
+
+	ok, err := confirm("Upload " + filePath + " despite the policy violations?")
+	if err != nil {
+		return fmt.Errorf("failed to confirm forced upload: %w", err)
+	}
+
+	if !ok {
+		log.Logger().Info().Str("file_path", filePath).Msg("forced upload declined")
+		return errors.New("upload aborted")
+	}
+
+	log.Logger().Warn().Str("file_path", filePath).Strs("violations", violations).
+		Msg("upload policy overridden with --force")
+
+	return nil
+}
+
+const bytesPerMB = 1024 * 1024
+
+type uploaded struct {
+	// name is the file name shown to people, e.g. on the preview page.
+	name     string
+	object   *minio.Object
+	original *minio.Object
+	// sanitized is true if the image sanitizer ran, removed lists what it removed.
+	sanitized bool
+	removed   []string
+}
+
+// prepareOptions are the per-command choices of the upload pipeline.
+type prepareOptions struct {
+	keepMetadata bool
+	// withOriginal and replace only apply to optimized images.
+	withOriginal bool
+	replace      bool
+}
+
+//nolint:funlen // The pipeline steps share the temporary copies and their cleanup.
+func uploadPrepared(
+	ctx context.Context,
+	c *config.Config,
+	mc *minio.Client,
+	filePath string,
+	opts prepareOptions,
+) (*uploaded, error) {
+	sanitized, err := sanitizeUpload(filePath, opts.keepMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		cleanupErr := sanitized.Cleanup()
+		if cleanupErr != nil {
+			log.Logger().Error().Err(cleanupErr).Msg("failed to remove sanitized copy")
+		}
+	}()
+
+	var optimized *optimize.Result
+	optimized, err = optimizeUpload(c, sanitized.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		cleanupErr := optimized.Cleanup()
+		if cleanupErr != nil {
+			log.Logger().Error().Err(cleanupErr).Msg("failed to remove optimized copy")
+		}
+	}()
+
+	up := &uploaded{
+		name:      filepath.Base(filePath),
+		object:    nil,
+		original:  nil,
+		sanitized: sanitized.Sanitized,
+		removed:   sanitized.Removed,
+	}
+
+	if optimized.Optimized && opts.withOriginal {
+		up.original, err = mc.UploadFile(ctx, sanitized.Path, filepath.Base(filePath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload original file: %w", err)
+		}
+
+		log.Logger().Info().
+			Str("object_key", up.original.Key).
+			Str("url", up.original.URL.String()).
+			Msg("original file uploaded to MinIO successfully")
+	}
+
+	up.object, err = mc.UploadFile(ctx, optimized.Path, filepath.Base(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	if optimized.Optimized && opts.replace {
+		err = replaceFile(filePath, optimized.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Logger().Info().Str("file_path", filePath).Msg("local file replaced with the optimized image")
+	}
+
+	return up, nil
+}
+
+// uploadDirectory streams the archive straight into a multipart upload,
+// only sanitized copies of images are written to disk.
+func uploadDirectory(
+	ctx context.Context,
+	mc *minio.Client,
+	dir string,
+	format archive.Format,
+	members []archive.Member,
+	keepMetadata bool,
+) (*uploaded, error) {
+	name, err := archive.Name(dir, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archive name: %w", err)
+	}
+
+	up := &uploaded{name: name, object: nil, original: nil, sanitized: false, removed: nil}
+
+	if keepMetadata {
+		log.Logger().Warn().Str("dir", dir).Msg("keeping image metadata due to --keep-metadata flag")
+	} else {
+		var results []*sanitize.Result
+		results, err = sanitizeMembers(members)
+		defer cleanupSanitized(results)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range results {
+			up.sanitized = true
+			for _, kind := range r.Removed {
+				if !slices.Contains(up.removed, kind) {
+					up.removed = append(up.removed, kind)
+				}
+			}
+		}
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(archive.Write(pw, format, members))
+	}()
+
+	up.object, err = mc.UploadStream(ctx, pr, name, format.ContentType())
+
+	// Unblocks the writer if the upload stopped before reading everything.
+	_ = pr.CloseWithError(err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload archive: %w", err)
+	}
+
+	log.Logger().Info().Str("dir", dir).Str("format", string(format)).Str("object_key", up.object.Key).
+		Msg("directory archived and uploaded")
+
+	return up, nil
+}
+
+// sanitizeMembers strips the metadata of the JPEG, PNG and WebP members,
+// they are archived from sanitized copies. The returned results have to be
+// cleaned up after the upload, also if an error is returned.
+func sanitizeMembers(members []archive.Member) ([]*sanitize.Result, error) {
+	var results []*sanitize.Result
+
+	for i := range members {
+		m := &members[i]
+		if !m.Info.Mode().IsRegular() {
+			continue
+		}
+
+		r, err := sanitize.Image(m.Path)
+		if err != nil {
+			return results, fmt.Errorf("failed to sanitize %s: %w", m.Name, err)
+		}
+
+		if !r.Sanitized {
+			continue
+		}
+
+		results = append(results, r)
+
+		if !r.Changed() {
+			continue
+		}
+
+		err = m.Replace(r.Path)
+		if err != nil {
+			return results, fmt.Errorf("failed to archive sanitized %s: %w", m.Name, err)
+		}
+
+		log.Logger().Info().Str("member", m.Name).Strs("removed", r.Removed).Msg("image metadata stripped")
+	}
+
+	return results, nil
+}
+
+func cleanupSanitized(results []*sanitize.Result) {
+	for _, r := range results {
+		err := r.Cleanup()
+		if err != nil {
+			log.Logger().Error().Err(err).Str("file_path", r.Path).Msg("failed to remove sanitized copy")
+		}
+	}
+}
+
+// uploadPreview uploads an HTML page embedding the uploaded object,
+// chat apps unfurl it with a title, description and thumbnail.
+func uploadPreview(ctx context.Context, mc *minio.Client, up *uploaded) (*minio.Object, error) {
+	page, err := renderPreview(up)
+	if err != nil {
+		return nil, err
+	}
+
+	var obj *minio.Object
+	obj, err = mc.UploadData(ctx, page, "preview.html", previewContentType, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload preview page: %w", err)
+	}
+
+	log.Logger().Info().Str("object_key", obj.Key).Str("kind", string(preview.KindOf(up.object.ContentType))).
+		Msg("preview page uploaded")
+
+	return obj, nil
+}
+
+const previewContentType = "text/html; charset=utf-8"
+
+func renderPreview(up *uploaded) ([]byte, error) {
+	page, err := preview.Render(preview.Page{
+		Name:        up.name,
+		URL:         up.object.URL.String(),
+		ContentType: up.object.ContentType,
+		Size:        up.object.Size,
+		Expires:     up.object.Expires,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render preview page: %w", err)
+	}
+
+	return page, nil
+}
+
+func optimizeUpload(c *config.Config, filePath string) (*optimize.Result, error) {
+	if !c.ImageOptimize {
+		return &optimize.Result{
+			Path:         filePath,
+			Optimized:    false,
+			OriginalSize: 0,
+			Size:         0,
+			Width:        0,
+			Height:       0,
+		}, nil
+	}
+
+	r, err := optimize.Image(filePath, optimize.Options{
+		MaxDimension: c.ImageMaxDimension,
+		Quality:      c.ImageQuality,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to optimize image: %w", err)
+	}
+
+	if !r.Optimized {
+		log.Logger().Info().Str("file_path", filePath).Msg("image not optimized, the original is already smaller")
+		return r, nil
+	}
+
+	savedPercent := fmt.Sprintf("%.1f%%", float64(r.Saved())*percent/float64(r.OriginalSize))
+
+	log.Logger().Info().
+		Str("file_path", filePath).
+		Int("width", r.Width).
+		Int("height", r.Height).
+		Int64("original_size", r.OriginalSize).
+		Int64("optimized_size", r.Size).
+		Int64("saved", r.Saved()).
+		Str("saved_percent", savedPercent).
+		Msg("image optimized")
+
+	_, err = fmt.Fprintf(
+		os.Stderr,
+		"INFO: optimized %s to %dx%d, %s instead of %s, saved %s (%s)\n",
+		filepath.Base(filePath),
+		r.Width,
+		r.Height,
+		preview.FormatSize(r.Size),
+		preview.FormatSize(r.OriginalSize),
+		preview.FormatSize(r.Saved()),
+		savedPercent,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to report saved size: %w", err)
+	}
+
+	return r, nil
+}
+
+const percent = 100
+
+func replaceFile(dst string, src string) error {
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read optimized image: %w", err)
+	}
+
+	var info os.FileInfo
+	info, err = os.Stat(dst)
+	if err != nil {
+		return fmt.Errorf("failed to stat original file: %w", err)
+	}
+
+	// Write next to the original so the rename stays on the same file system.
+	tmp := dst + ".minly.tmp"
+
+	err = os.WriteFile(tmp, b, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to write replacement: %w", err)
+	}
+
+	err = os.Rename(tmp, dst)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to replace original file: %w", err)
+	}
+
+	return nil
+}
+
+func sanitizeUpload(filePath string, keepMetadata bool) (*sanitize.Result, error) {
+	if keepMetadata {
+		log.Logger().Warn().Str("file_path", filePath).Msg("keeping image metadata due to --keep-metadata flag")
+		return &sanitize.Result{Path: filePath, Sanitized: false, Removed: nil}, nil
+	}
+
+	r, err := sanitize.Image(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sanitize image: %w", err)
+	}
+
+	switch {
+	case r.Changed():
+		log.Logger().Info().Str("file_path", filePath).Strs("removed", r.Removed).
+			Msg("image metadata stripped")
+	case r.Sanitized:
+		log.Logger().Info().Str("file_path", filePath).Msg("image has no metadata to strip")
+	}
+
+	return r, nil
 }
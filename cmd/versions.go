@@ -0,0 +1,368 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/devusSs/minly/internal/config"
+	"github.com/devusSs/minly/internal/log"
+	"github.com/devusSs/minly/internal/minio"
+	"github.com/devusSs/minly/internal/preview"
+	"github.com/devusSs/minly/internal/sanitize"
+	"github.com/devusSs/minly/internal/storage"
+	"github.com/devusSs/minly/internal/yourls"
+)
+
+var filesReplaceCmd = &cobra.Command{
+	Use:   "replace <id> <file>",
+	Short: "Uploads a new version of a file and points its short link to it",
+	Long: `Uploads a new version under the same object key and points the short link to it.
+The bucket needs versioning ("minly bucket versioning on") so earlier versions are kept, and
+updating the short link needs the YOURLS API Edit URL plugin.`,
+	Args: cobra.ExactArgs(versionArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := versionedFile(args[0])
+		logErr(err, "failed to find file")
+
+		filePath := args[1]
+
+		var c *config.Config
+		c, err = fileConfig(f)
+		logErr(err, "failed to load config")
+
+		var info os.FileInfo
+		info, err = os.Stat(filePath)
+		logErr(err, "failed to stat file")
+
+		if info.IsDir() {
+			logErr(errors.New("file path is a directory"), "only files can be replaced")
+		}
+
+		err = checkUploadPolicy(c, filePath, filesForce)
+		logErr(err, "upload blocked by policy")
+
+		var sanitized *sanitize.Result
+		sanitized, err = sanitizeUpload(filePath, filesKeepMetadata)
+		logErr(err, "failed to sanitize image")
+
+		defer func() {
+			cleanupErr := sanitized.Cleanup()
+			if cleanupErr != nil {
+				log.Logger().Error().Err(cleanupErr).Msg("failed to remove sanitized copy")
+			}
+		}()
+
+		var mc *minio.Client
+		var yc *yourls.Client
+		mc, yc, err = newUploadClients(c)
+		logErr(err, "failed to setup clients")
+
+		// Tags given with --tag on the first upload are kept for the new version.
+		var tags map[string]string
+		tags, err = uploadLabels(c, mc, filepath.Base(filePath), userTagFlags(f.Tags), nil)
+		logErr(err, "failed to set labels")
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		var obj *minio.Object
+		obj, err = mc.ReplaceFile(ctx, f.ObjectKey, sanitized.Path, filepath.Base(filePath))
+		if errors.Is(err, minio.ErrVersioningDisabled) {
+			logErr(err, "run 'minly bucket versioning on' first")
+		}
+		logErr(err, "failed to upload new version")
+
+		log.Logger().Info().Str("object_key", obj.Key).Str("version_id", obj.VersionID).
+			Msg("new version uploaded successfully")
+
+		f.SHA256 = obj.SHA256
+		f.Tags = tags
+		f.Sanitized = sanitized.Sanitized
+		f.MetadataRemoved = sanitized.Removed
+
+		err = relinkFile(ctx, mc, yc, f, &uploaded{
+			name:      filepath.Base(filePath),
+			object:    obj,
+			original:  nil,
+			sanitized: sanitized.Sanitized,
+			removed:   sanitized.Removed,
+		})
+		logErr(err, "failed to update short link")
+
+		cmd.Printf("Replaced %s with version %s, %s now serves it\n", f.ObjectKey, obj.VersionID, f.YOURLSLink)
+	},
+}
+
+var filesVersionsCmd = &cobra.Command{
+	Use:   "versions <id>",
+	Short: "Lists the versions of a file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		f, err := versionedFile(args[0])
+		logErr(err, "failed to find file")
+
+		var c *config.Config
+		c, err = fileConfig(f)
+		logErr(err, "failed to load config")
+
+		var mc *minio.Client
+		mc, err = newBucketClient(c)
+		logErr(err, "failed to setup MinIO client")
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		var versions []minio.ObjectVersion
+		versions, err = mc.Versions(ctx, f.ObjectKey)
+		logErr(err, "failed to list versions")
+
+		err = printVersionsAsTable(f, versions)
+		logErr(err, "failed to print versions as table")
+	},
+}
+
+var filesRestoreCmd = &cobra.Command{
+	Use:   "restore <id> <version>",
+	Short: "Makes an earlier version the latest one and points the short link to it",
+	Args:  cobra.ExactArgs(versionArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := versionedFile(args[0])
+		logErr(err, "failed to find file")
+
+		var c *config.Config
+		c, err = fileConfig(f)
+		logErr(err, "failed to load config")
+
+		var mc *minio.Client
+		var yc *yourls.Client
+		mc, yc, err = newUploadClients(c)
+		logErr(err, "failed to setup clients")
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		var obj *minio.Object
+		obj, err = mc.RestoreVersion(ctx, f.ObjectKey, args[1])
+		logErr(err, "failed to restore version")
+
+		log.Logger().Info().Str("object_key", obj.Key).Str("restored", args[1]).
+			Str("version_id", obj.VersionID).Msg("version restored successfully")
+
+		// The checksum of earlier versions is not recorded.
+		f.SHA256 = ""
+
+		var up *uploaded
+		up, err = restoredUpload(ctx, mc, obj)
+		logErr(err, "failed to get restored version")
+
+		err = relinkFile(ctx, mc, yc, f, up)
+		logErr(err, "failed to update short link")
+
+		cmd.Printf("Restored version %s of %s as %s, %s now serves it\n",
+			args[1], f.ObjectKey, obj.VersionID, f.YOURLSLink)
+	},
+}
+
+var (
+	filesForce        bool
+	filesKeepMetadata bool
+)
+
+const versionArgs = 2
+
+func init() {
+	filesCmd.AddCommand(filesReplaceCmd, filesVersionsCmd, filesRestoreCmd)
+
+	filesReplaceCmd.Flags().
+		BoolVar(&filesForce, "force", false, "upload despite upload policy violations after confirmation")
+	filesReplaceCmd.Flags().
+		BoolVar(&filesKeepMetadata, "keep-metadata", false, "do not strip EXIF, XMP and IPTC metadata from images")
+}
+
+// versionedFile returns the history entry with id, it needs an object key.
+func versionedFile(id string) (*storage.File, error) {
+	f := findHistoryFile(func(f *storage.File) bool { return f.ID == id })
+	if f == nil {
+		return nil, fmt.Errorf("no file with ID %s in the history, see 'minly files'", id)
+	}
+
+	if f.ObjectKey == "" {
+		return nil, fmt.Errorf("file %s was uploaded by an older minly version without recording its object key", id)
+	}
+
+	return f, nil
+}
+
+// fileConfig loads the config with the bucket and link mode of f.
+func fileConfig(f *storage.File) (*config.Config, error) {
+	overrides := map[string]any{"minio_link_mode": "presigned"}
+	if f.Permanent {
+		overrides["minio_link_mode"] = "public"
+	}
+
+	if f.Bucket != "" {
+		overrides["minio_bucket_name"] = f.Bucket
+	}
+
+	c, err := config.Load(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return c, nil
+}
+
+// userTagFlags returns the tags of a history entry that were not added by
+// minly, as --tag values.
+func userTagFlags(tags map[string]string) []string {
+	auto := map[string]bool{
+		labelVersion:      true,
+		labelProject:      true,
+		labelUploaderHost: true,
+		labelOriginalName: true,
+	}
+
+	var flags []string
+	for _, k := range sortedKeys(tags) {
+		if !auto[k] {
+			flags = append(flags, k+"="+tags[k])
+		}
+	}
+
+	return flags
+}
+
+// restoredUpload returns the restored object with the name and content
+// type of the version, the preview page shows them.
+func restoredUpload(ctx context.Context, mc *minio.Client, obj *minio.Object) (*uploaded, error) {
+	info, err := mc.Stat(ctx, obj.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat restored version: %w", err)
+	}
+
+	obj.ContentType = info.ContentType
+
+	name := info.Filename
+	if name == "" {
+		name = path.Base(obj.Key)
+	}
+
+	return &uploaded{name: name, object: obj, original: nil, sanitized: false, removed: nil}, nil
+}
+
+// relinkFile points the short link of f to the new version and updates the
+// history. Public links always serve the latest version and are kept. A
+// preview page is rendered again for the version under its key, the short
+// link keeps pointing at the page.
+func relinkFile(ctx context.Context, mc *minio.Client, yc *yourls.Client, f *storage.File, up *uploaded) error {
+	f.VersionID = up.object.VersionID
+
+	target := up.object
+	if f.PreviewKey != "" {
+		var err error
+		target, err = refreshPreview(ctx, mc, f.PreviewKey, up)
+		if err != nil {
+			return err
+		}
+	}
+
+	var updateErr error
+	if !f.Permanent {
+		f.MinioLink = up.object.URL.String()
+		f.MinioLinkExpires = up.object.Expires
+
+		updateErr = yc.Update(ctx, f.YOURLSLink, target.URL.String())
+		if errors.Is(updateErr, yourls.ErrUpdateUnsupported) {
+			updateErr = fmt.Errorf(
+				"%w, open %s or create a new short link for it",
+				updateErr,
+				target.URL.String(),
+			)
+		}
+	}
+
+	err := fs.Update(f)
+	if err != nil {
+		return errors.Join(updateErr, fmt.Errorf("failed to update file metadata in storage: %w", err))
+	}
+
+	if updateErr != nil {
+		return fmt.Errorf("failed to update YOURLS target: %w", updateErr)
+	}
+
+	log.Logger().Info().Str("short_url", f.YOURLSLink).Str("version_id", f.VersionID).
+		Msg("short link updated successfully")
+
+	return nil
+}
+
+// refreshPreview replaces the preview page at key with one embedding the
+// new version.
+func refreshPreview(ctx context.Context, mc *minio.Client, key string, up *uploaded) (*minio.Object, error) {
+	page, err := renderPreview(up)
+	if err != nil {
+		return nil, err
+	}
+
+	var obj *minio.Object
+	obj, err = mc.PutData(ctx, key, page, previewContentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace preview page: %w", err)
+	}
+
+	log.Logger().Info().Str("object_key", obj.Key).Str("version_id", up.object.VersionID).
+		Msg("preview page replaced")
+
+	return obj, nil
+}
+
+func printVersionsAsTable(f *storage.File, versions []minio.ObjectVersion) error {
+	if len(versions) == 0 {
+		return errors.New("no versions found")
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Version ID", "Modified", "Size", "Latest", "Linked"})
+
+	for _, v := range versions {
+		size := preview.FormatSize(v.Size)
+		if v.DeleteMarker {
+			size = "deleted"
+		}
+
+		err := table.Append([]string{
+			v.VersionID,
+			v.LastModified.Format(time.RFC3339),
+			size,
+			yesNo(v.Latest),
+			yesNo(f.VersionID != "" && v.VersionID == f.VersionID),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to append row to table: %w", err)
+		}
+	}
+
+	err := table.Render()
+	if err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+
+	return nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
@@ -0,0 +1,46 @@
+package cmd_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/devusSs/minly/cmd"
+)
+
+func TestUserTagFlags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		tags map[string]string
+		want []string
+	}{
+		{name: "no tags", tags: nil, want: nil},
+		{
+			name: "only automatic tags",
+			tags: map[string]string{
+				"minly-version": "1.0.0",
+				"minly-project": "demo",
+				"uploader-host": "laptop",
+				"original-name": "a.pdf",
+			},
+			want: nil,
+		},
+		{
+			name: "user tags are sorted",
+			tags: map[string]string{"team": "infra", "env": "prod", "original-name": "a.pdf"},
+			want: []string{"env=prod", "team=infra"},
+		},
+		{name: "value with equals sign", tags: map[string]string{"q": "a=b"}, want: []string{"q=a=b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := cmd.UserTagFlags(tt.tags); !slices.Equal(got, tt.want) {
+				t.Errorf("UserTagFlags(%v) = %v, want %v", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
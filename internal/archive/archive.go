@@ -0,0 +1,319 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+type Format string
+
+const (
+	FormatZip Format = "zip"
+	FormatTgz Format = "tgz"
+)
+
+var ErrEmpty = errors.New("directory does not contain any files")
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatZip, FormatTgz:
+		return Format(s), nil
+	default:
+		return "", fmt.Errorf("unknown archive format %s, must be zip or tgz", s)
+	}
+}
+
+func (f Format) Extension() string {
+	if f == FormatTgz {
+		return ".tar.gz"
+	}
+
+	return ".zip"
+}
+
+func (f Format) ContentType() string {
+	if f == FormatTgz {
+		return "application/gzip"
+	}
+
+	return "application/zip"
+}
+
+type Member struct {
+	// Path is the file on disk, Name the slash separated name in the archive.
+	Path string
+	Name string
+	Info fs.FileInfo
+}
+
+// Replace makes the member read its content from path, e.g. a sanitized
+// copy. The name, mode and modification time in the archive are kept.
+func (m *Member) Replace(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	m.Path = path
+	m.Info = sizedInfo{FileInfo: m.Info, size: info.Size()}
+
+	return nil
+}
+
+// sizedInfo is the file info of a member with the size of its replacement.
+type sizedInfo struct {
+	fs.FileInfo
+
+	size int64
+}
+
+func (i sizedInfo) Size() int64 {
+	return i.size
+}
+
+type Stats struct {
+	Members int
+	Size    int64
+}
+
+// Collect walks dir and returns the members of the archive. The names are
+// prefixed with the directory name so extracting recreates the directory.
+func Collect(dir string) ([]Member, Stats, error) {
+	var stats Stats
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, stats, fmt.Errorf("failed to stat directory: %w", err)
+	}
+
+	if !info.IsDir() {
+		return nil, stats, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	var ig *Ignore
+	ig, err = LoadIgnore(filepath.Join(dir, IgnoreFile))
+	if err != nil {
+		return nil, stats, err
+	}
+
+	var root string
+	root, err = rootName(dir)
+	if err != nil {
+		return nil, stats, err
+	}
+
+	var members []Member
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path of %s: %w", p, err)
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		// The ignore file configures the upload, it is not part of it.
+		if rel == IgnoreFile && !d.IsDir() {
+			return nil
+		}
+
+		if rel != "." && ig.Match(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to get file info of %s: %w", p, err)
+		}
+
+		// Sockets, devices and pipes cannot be archived in a portable way.
+		if !info.Mode().IsRegular() && !info.IsDir() && info.Mode()&fs.ModeSymlink == 0 {
+			return nil
+		}
+
+		members = append(members, Member{Path: p, Name: path.Join(root, rel), Info: info})
+
+		if info.Mode().IsRegular() {
+			stats.Members++
+			stats.Size += info.Size()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, stats, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	if stats.Members == 0 {
+		return nil, stats, ErrEmpty
+	}
+
+	return members, stats, nil
+}
+
+// Name returns the object name of the archive of dir, e.g. photos.tar.gz.
+func Name(dir string, format Format) (string, error) {
+	root, err := rootName(dir)
+	if err != nil {
+		return "", err
+	}
+
+	return root + format.Extension(), nil
+}
+
+func rootName(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path of %s: %w", dir, err)
+	}
+
+	root := filepath.Base(abs)
+	if root == string(filepath.Separator) {
+		return "", errors.New("cannot archive the root directory")
+	}
+
+	return root, nil
+}
+
+func Write(w io.Writer, format Format, members []Member) error {
+	switch format {
+	case FormatZip:
+		return writeZip(w, members)
+	case FormatTgz:
+		return writeTgz(w, members)
+	default:
+		return fmt.Errorf("unknown archive format %s", format)
+	}
+}
+
+func writeZip(w io.Writer, members []Member) error {
+	zw := zip.NewWriter(w)
+
+	for _, m := range members {
+		h, err := zip.FileInfoHeader(m.Info)
+		if err != nil {
+			return fmt.Errorf("failed to create zip header for %s: %w", m.Name, err)
+		}
+
+		h.Name = m.Name
+		if m.Info.IsDir() {
+			h.Name += "/"
+		}
+
+		if m.Info.Mode().IsRegular() {
+			h.Method = zip.Deflate
+		}
+
+		var fw io.Writer
+		fw, err = zw.CreateHeader(h)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to zip: %w", m.Name, err)
+		}
+
+		err = writeContent(fw, m)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := zw.Close()
+	if err != nil {
+		return fmt.Errorf("failed to finish zip: %w", err)
+	}
+
+	return nil
+}
+
+func writeTgz(w io.Writer, members []Member) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	for _, m := range members {
+		var link string
+		if m.Info.Mode()&fs.ModeSymlink != 0 {
+			var err error
+			link, err = os.Readlink(m.Path)
+			if err != nil {
+				return fmt.Errorf("failed to read symlink %s: %w", m.Path, err)
+			}
+		}
+
+		h, err := tar.FileInfoHeader(m.Info, link)
+		if err != nil {
+			return fmt.Errorf("failed to create tar header for %s: %w", m.Name, err)
+		}
+
+		h.Name = m.Name
+		if m.Info.IsDir() {
+			h.Name += "/"
+		}
+
+		err = tw.WriteHeader(h)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to tar: %w", m.Name, err)
+		}
+
+		if m.Info.Mode().IsRegular() {
+			err = writeContent(tw, m)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err := tw.Close()
+	if err != nil {
+		return fmt.Errorf("failed to finish tar: %w", err)
+	}
+
+	err = gw.Close()
+	if err != nil {
+		return fmt.Errorf("failed to finish gzip: %w", err)
+	}
+
+	return nil
+}
+
+func writeContent(w io.Writer, m Member) error {
+	switch {
+	case m.Info.Mode().IsRegular():
+		f, err := os.Open(m.Path)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", m.Path, err)
+		}
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", m.Path, err)
+		}
+	case m.Info.Mode()&fs.ModeSymlink != 0:
+		// Zip stores the link target as the content of a symlink entry.
+		target, err := os.Readlink(m.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read symlink %s: %w", m.Path, err)
+		}
+
+		_, err = io.WriteString(w, target)
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", m.Path, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/devusSs/minly/internal/archive"
+)
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, archive.IgnoreFile), "*.log\nbuild/\n")
+	writeFile(t, filepath.Join(dir, "main.go"), "package main")
+	writeFile(t, filepath.Join(dir, "debug.log"), "log")
+	writeFile(t, filepath.Join(dir, "build", "out"), "binary")
+	writeFile(t, filepath.Join(dir, "src", "a.go"), "package src")
+
+	members, stats, err := archive.Collect(dir)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	root := filepath.Base(dir)
+
+	var names []string
+	for _, m := range members {
+		names = append(names, m.Name)
+	}
+
+	want := []string{root, path.Join(root, "main.go"), path.Join(root, "src"), path.Join(root, "src", "a.go")}
+	if !slices.Equal(names, want) {
+		t.Errorf("members = %v, want %v", names, want)
+	}
+
+	if stats.Members != 2 {
+		t.Errorf("Members = %d, want 2", stats.Members)
+	}
+}
+
+func TestCollectOnlyIgnoreFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, archive.IgnoreFile), "*.log\n")
+
+	_, _, err := archive.Collect(dir)
+	if !errors.Is(err, archive.ErrEmpty) {
+		t.Fatalf("Collect() error = %v, want %v", err, archive.ErrEmpty)
+	}
+}
+
+func TestWriteReplacedMember(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []archive.Format{archive.FormatZip, archive.FormatTgz} {
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "photo.jpg"), "original with metadata")
+
+			members, _, err := archive.Collect(dir)
+			if err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			replacement := filepath.Join(t.TempDir(), "sanitized.jpg")
+			writeFile(t, replacement, "sanitized")
+
+			m := &members[len(members)-1]
+
+			err = m.Replace(replacement)
+			if err != nil {
+				t.Fatalf("Replace() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+
+			err = archive.Write(&buf, format, members)
+			if err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			got := readMember(t, format, buf.Bytes(), path.Join(filepath.Base(dir), "photo.jpg"))
+			if got != "sanitized" {
+				t.Errorf("archived content = %q, want %q", got, "sanitized")
+			}
+		})
+	}
+}
+
+func readMember(t *testing.T, format archive.Format, data []byte, name string) string {
+	t.Helper()
+
+	if format == archive.FormatZip {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := zr.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		b, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(b)
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err != nil {
+			t.Fatalf("%s not found in tar: %v", name, err)
+		}
+
+		if h.Name == name {
+			b, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return string(b)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,159 @@
+package archive
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const IgnoreFile = ".minlyignore"
+
+type rule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Ignore matches paths relative to the archived directory against
+// patterns in gitignore syntax, the last matching pattern wins.
+type Ignore struct {
+	rules []rule
+}
+
+func LoadIgnore(path string) (*Ignore, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Ignore{rules: nil}, nil
+		}
+
+		return nil, fmt.Errorf("failed to open ignore file: %w", err)
+	}
+	defer f.Close()
+
+	ig := &Ignore{rules: nil}
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var r *rule
+		r, err = parseRule(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern in %s line %d: %w", path, line, err)
+		}
+
+		if r != nil {
+			ig.rules = append(ig.rules, *r)
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+
+	return ig, nil
+}
+
+func (ig *Ignore) Match(rel string, isDir bool) bool {
+	ignored := false
+
+	for _, r := range ig.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+
+		if r.pattern.MatchString(rel) {
+			ignored = !r.negate
+		}
+	}
+
+	return ignored
+}
+
+func parseRule(line string) (*rule, error) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil, nil //nolint:nilnil // Blank lines and comments are not rules.
+	}
+
+	r := &rule{pattern: nil, negate: false, dirOnly: false}
+
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	}
+
+	// A backslash escapes a leading # or !.
+	line = strings.TrimPrefix(line, `\`)
+
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	// Patterns with a slash are relative to the directory root,
+	// all others match at any depth.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	if line == "" {
+		return nil, errors.New("empty pattern")
+	}
+
+	expr := globToRegexp(line)
+	if anchored {
+		expr = "^" + expr + "$"
+	} else {
+		expr = "^(.*/)?" + expr + "$"
+	}
+
+	var err error
+	r.pattern, err = regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile %s: %w", line, err)
+	}
+
+	return r, nil
+}
+
+func globToRegexp(glob string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**/") {
+				b.WriteString("(.*/)?")
+				i += 2
+			} else if strings.HasPrefix(glob[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return b.String()
+}
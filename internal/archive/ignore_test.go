@@ -0,0 +1,120 @@
+package archive_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devusSs/minly/internal/archive"
+)
+
+func TestIgnoreMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		rules []string
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{name: "no rules", rules: nil, path: "a.log", isDir: false, want: false},
+		{name: "extension at the root", rules: []string{"*.log"}, path: "a.log", isDir: false, want: true},
+		{name: "extension at any depth", rules: []string{"*.log"}, path: "x/y/a.log", isDir: false, want: true},
+		{name: "extension is not a prefix", rules: []string{"*.log"}, path: "a.log.txt", isDir: false, want: false},
+		{name: "star does not cross slashes", rules: []string{"docs/*.md"}, path: "docs/a/b.md", isDir: false, want: false},
+		{name: "slash anchors the pattern", rules: []string{"docs/*.md"}, path: "x/docs/a.md", isDir: false, want: false},
+		{name: "anchored pattern", rules: []string{"docs/*.md"}, path: "docs/a.md", isDir: false, want: true},
+		{name: "leading slash at the root", rules: []string{"/build"}, path: "build", isDir: true, want: true},
+		{name: "leading slash below the root", rules: []string{"/build"}, path: "src/build", isDir: true, want: false},
+		{name: "directory rule on a directory", rules: []string{"build/"}, path: "src/build", isDir: true, want: true},
+		{name: "directory rule on a file", rules: []string{"build/"}, path: "build", isDir: false, want: false},
+		{name: "double star prefix at the root", rules: []string{"**/cache"}, path: "cache", isDir: true, want: true},
+		{name: "double star prefix nested", rules: []string{"**/cache"}, path: "a/b/cache", isDir: true, want: true},
+		{name: "double star suffix", rules: []string{"logs/**"}, path: "logs/a/b.txt", isDir: false, want: true},
+		{name: "double star suffix needs content", rules: []string{"logs/**"}, path: "logs", isDir: true, want: false},
+		{name: "double star in the middle", rules: []string{"a/**/b"}, path: "a/x/y/b", isDir: false, want: true},
+		{name: "double star matches no directory", rules: []string{"a/**/b"}, path: "a/b", isDir: false, want: true},
+		{name: "question mark", rules: []string{"?.txt"}, path: "a.txt", isDir: false, want: true},
+		{name: "question mark is one character", rules: []string{"?.txt"}, path: "ab.txt", isDir: false, want: false},
+		{name: "character class", rules: []string{"[abc].txt"}, path: "b.txt", isDir: false, want: true},
+		{name: "character class miss", rules: []string{"[abc].txt"}, path: "d.txt", isDir: false, want: false},
+		{name: "negated character class", rules: []string{"[!abc].txt"}, path: "d.txt", isDir: false, want: true},
+		{name: "negated character class miss", rules: []string{"[!abc].txt"}, path: "a.txt", isDir: false, want: false},
+		{name: "unclosed bracket is literal", rules: []string{"[abc.txt"}, path: "[abc.txt", isDir: false, want: true},
+		{name: "regexp characters are literal", rules: []string{"a+b.txt"}, path: "aab.txt", isDir: false, want: false},
+		{name: "negation re-includes", rules: []string{"*.log", "!keep.log"}, path: "keep.log", isDir: false, want: false},
+		{name: "negation keeps others", rules: []string{"*.log", "!keep.log"}, path: "a.log", isDir: false, want: true},
+		{name: "last rule wins", rules: []string{"!keep.log", "*.log"}, path: "keep.log", isDir: false, want: true},
+		{name: "comments are skipped", rules: []string{"# *.log"}, path: "a.log", isDir: false, want: false},
+		{name: "escaped hash", rules: []string{`\#notes`}, path: "#notes", isDir: false, want: true},
+		{name: "escaped exclamation mark", rules: []string{`\!important`}, path: "!important", isDir: false, want: true},
+		{name: "trailing spaces are trimmed", rules: []string{"*.tmp  "}, path: "a.tmp", isDir: false, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ig, err := archive.LoadIgnore(writeIgnore(t, tt.rules))
+			if err != nil {
+				t.Fatalf("LoadIgnore() error = %v", err)
+			}
+
+			if got := ig.Match(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadIgnoreErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		rules []string
+	}{
+		{name: "only a slash", rules: []string{"/"}},
+		{name: "only an exclamation mark", rules: []string{"!"}},
+		{name: "invalid character range", rules: []string{"*.log", "[z-a].txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := archive.LoadIgnore(writeIgnore(t, tt.rules))
+			if err == nil {
+				t.Fatal("LoadIgnore() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestLoadIgnoreMissingFile(t *testing.T) {
+	t.Parallel()
+
+	ig, err := archive.LoadIgnore(filepath.Join(t.TempDir(), archive.IgnoreFile))
+	if err != nil {
+		t.Fatalf("LoadIgnore() error = %v", err)
+	}
+
+	if ig.Match("a.log", false) {
+		t.Error("Match() = true without an ignore file")
+	}
+}
+
+func writeIgnore(t *testing.T, rules []string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), archive.IgnoreFile)
+
+	err := os.WriteFile(path, []byte(strings.Join(rules, "\n")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
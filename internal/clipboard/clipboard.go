@@ -14,3 +14,16 @@ func Write(text string) error {
 
 	return nil
 }
+
+func Supported() bool {
+	return !clipboard.Unsupported
+}
+
+func Read() (string, error) {
+	text, err := clipboard.ReadAll()
+	if err != nil {
+		return "", fmt.Errorf("failed to read from clipboard: %w", err)
+	}
+
+	return text, nil
+}
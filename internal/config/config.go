@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/devusSs/minly/internal/paths"
 )
 
 type Config struct {
@@ -21,7 +23,69 @@ type Config struct {
 	MinioLinkExpiry time.Duration `json:"minio_link_expiry" env:"MINIO_LINK_EXPIRY" envDefault:"24h"`
 	YOURLSEndpoint  *url.URL      `json:"yourls_endpoint"   env:"YOURLS_ENDPOINT"   envDefault:"http://localhost:80/yourls-api.php"`
 
+	MinioBucketNameStrict bool   `json:"minio_bucket_name_strict" env:"MINIO_BUCKET_NAME_STRICT" envDefault:"false"`
+	MinioRegionPattern    string `json:"minio_region_pattern"     env:"MINIO_REGION_PATTERN"     envDefault:"^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$"`
+
+	MinioProvider   string `json:"minio_provider"   env:"MINIO_PROVIDER"   envDefault:"minio"`
+	MinioAddressing string `json:"minio_addressing" env:"MINIO_ADDRESSING" envDefault:""`
+	MinioSignature  string `json:"minio_signature"  env:"MINIO_SIGNATURE"  envDefault:""`
+
+	MinioObjectPrefix  string `json:"minio_object_prefix"   env:"MINIO_OBJECT_PREFIX"   envDefault:""`
+	MinioLinkMode      string `json:"minio_link_mode"       env:"MINIO_LINK_MODE"       envDefault:"presigned"`
+	MinioPublicPrefix  string `json:"minio_public_prefix"   env:"MINIO_PUBLIC_PREFIX"   envDefault:"public/"`
+	MinioPublicBaseURL string `json:"minio_public_base_url" env:"MINIO_PUBLIC_BASE_URL" envDefault:""`
+
+	MinioCredentialSource     string        `json:"minio_credential_source"       env:"MINIO_CREDENTIAL_SOURCE"       envDefault:"static"`
+	MinioCredentialFile       string        `json:"minio_credential_file"         env:"MINIO_CREDENTIAL_FILE"         envDefault:""`
+	MinioCredentialProfile    string        `json:"minio_credential_profile"      env:"MINIO_CREDENTIAL_PROFILE"      envDefault:""`
+	MinioSTSEndpoint          string        `json:"minio_sts_endpoint"            env:"MINIO_STS_ENDPOINT"            envDefault:""`
+	MinioRoleARN              string        `json:"minio_role_arn"                env:"MINIO_ROLE_ARN"                envDefault:""`
+	MinioRoleSessionName      string        `json:"minio_role_session_name"       env:"MINIO_ROLE_SESSION_NAME"       envDefault:"minly"`
+	MinioWebIdentityTokenFile string        `json:"minio_web_identity_token_file" env:"MINIO_WEB_IDENTITY_TOKEN_FILE" envDefault:""`
+	MinioCredentialDuration   time.Duration `json:"minio_credential_duration"     env:"MINIO_CREDENTIAL_DURATION"     envDefault:"1h"`
+
+	MinioTLSCAFile             string `json:"minio_tls_ca_file"              env:"MINIO_TLS_CA_FILE"              envDefault:""`
+	MinioTLSCertFile           string `json:"minio_tls_cert_file"            env:"MINIO_TLS_CERT_FILE"            envDefault:""`
+	MinioTLSMinVersion         string `json:"minio_tls_min_version"          env:"MINIO_TLS_MIN_VERSION"          envDefault:"1.2"`
+	MinioTLSServerName         string `json:"minio_tls_server_name"          env:"MINIO_TLS_SERVER_NAME"          envDefault:""`
+	MinioTLSInsecureSkipVerify bool   `json:"minio_tls_insecure_skip_verify" env:"MINIO_TLS_INSECURE_SKIP_VERIFY" envDefault:"false"`
+
+	YOURLSTLSCAFile             string `json:"yourls_tls_ca_file"              env:"YOURLS_TLS_CA_FILE"              envDefault:""`
+	YOURLSTLSCertFile           string `json:"yourls_tls_cert_file"            env:"YOURLS_TLS_CERT_FILE"            envDefault:""`
+	YOURLSTLSMinVersion         string `json:"yourls_tls_min_version"          env:"YOURLS_TLS_MIN_VERSION"          envDefault:"1.2"`
+	YOURLSTLSServerName         string `json:"yourls_tls_server_name"          env:"YOURLS_TLS_SERVER_NAME"          envDefault:""`
+	YOURLSTLSInsecureSkipVerify bool   `json:"yourls_tls_insecure_skip_verify" env:"YOURLS_TLS_INSECURE_SKIP_VERIFY" envDefault:"false"`
+
+	HTTPProxy                 string        `json:"http_proxy"                   env:"HTTP_PROXY"                   envDefault:""`
+	HTTPDialTimeout           time.Duration `json:"http_dial_timeout"            env:"HTTP_DIAL_TIMEOUT"            envDefault:"10s"`
+	HTTPTLSHandshakeTimeout   time.Duration `json:"http_tls_handshake_timeout"   env:"HTTP_TLS_HANDSHAKE_TIMEOUT"   envDefault:"10s"`
+	HTTPResponseHeaderTimeout time.Duration `json:"http_response_header_timeout" env:"HTTP_RESPONSE_HEADER_TIMEOUT" envDefault:"1m"`
+	HTTPKeepAlive             time.Duration `json:"http_keep_alive"              env:"HTTP_KEEP_ALIVE"              envDefault:"30s"`
+
+	UploadMaxSizeMB   int64    `json:"upload_max_size_mb"  env:"UPLOAD_MAX_SIZE_MB"  envDefault:"0"`
+	UploadAllowedMIME []string `json:"upload_allowed_mime" env:"UPLOAD_ALLOWED_MIME" envDefault:""`
+	UploadDeniedMIME  []string `json:"upload_denied_mime"  env:"UPLOAD_DENIED_MIME"  envDefault:""`
+	UploadDeniedNames []string `json:"upload_denied_names" env:"UPLOAD_DENIED_NAMES" envDefault:".env,.env.*,*.pem,*.key,id_rsa,id_dsa,id_ecdsa,id_ed25519,*.kdbx,*.p12,*.pfx"`
+	UploadScanSecrets bool     `json:"upload_scan_secrets" env:"UPLOAD_SCAN_SECRETS" envDefault:"true"`
+	UploadVerifyLink  bool     `json:"upload_verify_link"  env:"UPLOAD_VERIFY_LINK"  envDefault:"false"`
+
+	ImageOptimize     bool `json:"image_optimize"      env:"IMAGE_OPTIMIZE"      envDefault:"false"`
+	ImageMaxDimension int  `json:"image_max_dimension" env:"IMAGE_MAX_DIMENSION" envDefault:"1920"`
+	ImageQuality      int  `json:"image_quality"       env:"IMAGE_QUALITY"       envDefault:"85"`
+
+	ReceivePrefix    string        `json:"receive_prefix"      env:"RECEIVE_PREFIX"      envDefault:"incoming/"`
+	ReceiveMaxSizeMB int64         `json:"receive_max_size_mb" env:"RECEIVE_MAX_SIZE_MB" envDefault:"1024"`
+	ReceiveExpiry    time.Duration `json:"receive_expiry"      env:"RECEIVE_EXPIRY"      envDefault:"72h"`
+
+	SecretBackend    string `json:"secret_backend"     env:"SECRET_BACKEND"     envDefault:"keyring"`
+	SecretCommand    string `json:"secret_command"     env:"SECRET_COMMAND"     envDefault:""`
+	SecretSetCommand string `json:"secret_set_command" env:"SECRET_SET_COMMAND" envDefault:""`
+	// SecretMissingExitCode is the exit code of secret_command for a
+	// missing secret, 0 relies on the messages of well-known tools.
+	SecretMissingExitCode int `json:"secret_missing_exit_code" env:"SECRET_MISSING_EXIT_CODE" envDefault:"0"`
+
 	filePath string
+	sources  map[string]Source
 }
 
 func (c *Config) String() string {
@@ -32,6 +96,32 @@ func (c *Config) FilePath() string {
 	return c.filePath
 }
 
+func (c *Config) HTTPProxyURL() *url.URL {
+	if c.HTTPProxy == "" {
+		return nil
+	}
+
+	// The value has been validated already.
+	u, _ := url.Parse(c.HTTPProxy)
+
+	return u
+}
+
+func (c *Config) MinioPublicBaseURLParsed() *url.URL {
+	if c.MinioPublicBaseURL == "" {
+		return nil
+	}
+
+	// The value has been validated already.
+	u, _ := url.Parse(c.MinioPublicBaseURL)
+
+	return u
+}
+
+func Default() *Config {
+	return newDefaultConfig()
+}
+
 func Read() (*Config, error) {
 	f, err := openConfigFile()
 	if err != nil {
@@ -117,14 +207,7 @@ func createConfigFile() (*os.File, error) {
 }
 
 func setupConfigDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get user home directory: %w", err)
-	}
-
-	configDir := filepath.Join(home, ".minly", "config")
-
-	err = os.MkdirAll(configDir, 0700)
+	configDir, err := paths.ConfigDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to create config directory: %w", err)
 	}
@@ -132,6 +215,36 @@ func setupConfigDir() (string, error) {
 	return configDir, nil
 }
 
+const defaultMinioCredentialDuration = 1 * time.Hour
+
+// No size limit by default, earlier versions uploaded files of any size.
+const defaultUploadMaxSizeMB = 0
+
+const (
+	defaultReceiveMaxSizeMB = 1024
+	defaultReceiveExpiry    = 72 * time.Hour
+)
+
+const (
+	defaultImageMaxDimension = 1920
+	defaultImageQuality      = 85
+)
+
+func defaultUploadDeniedNames() []string {
+	return []string{
+		".env", ".env.*", "*.pem", "*.key",
+		"id_rsa", "id_dsa", "id_ecdsa", "id_ed25519",
+		"*.kdbx", "*.p12", "*.pfx",
+	}
+}
+
+const (
+	defaultHTTPDialTimeout           = 10 * time.Second
+	defaultHTTPTLSHandshakeTimeout   = 10 * time.Second
+	defaultHTTPResponseHeaderTimeout = 1 * time.Minute
+	defaultHTTPKeepAlive             = 30 * time.Second
+)
+
 func newDefaultConfig() *Config {
 	return &Config{
 		ProjectName:     "minly",
@@ -143,6 +256,68 @@ func newDefaultConfig() *Config {
 		MinioRegion:     "us-east-1",
 		MinioLinkExpiry: minMinioLinkExpiry,
 		YOURLSEndpoint:  &url.URL{Scheme: "http", Host: "localhost:80", Path: "/yourls-api.php"},
-		filePath:        "",
+
+		MinioBucketNameStrict: false,
+		MinioRegionPattern:    defaultMinioRegionPattern,
+
+		MinioProvider:   "minio",
+		MinioAddressing: "",
+		MinioSignature:  "",
+
+		MinioObjectPrefix:  "",
+		MinioLinkMode:      "presigned",
+		MinioPublicPrefix:  "public/",
+		MinioPublicBaseURL: "",
+
+		MinioCredentialSource:     "static",
+		MinioCredentialFile:       "",
+		MinioCredentialProfile:    "",
+		MinioSTSEndpoint:          "",
+		MinioRoleARN:              "",
+		MinioRoleSessionName:      "minly",
+		MinioWebIdentityTokenFile: "",
+		MinioCredentialDuration:   defaultMinioCredentialDuration,
+
+		MinioTLSCAFile:             "",
+		MinioTLSCertFile:           "",
+		MinioTLSMinVersion:         "1.2",
+		MinioTLSServerName:         "",
+		MinioTLSInsecureSkipVerify: false,
+
+		YOURLSTLSCAFile:             "",
+		YOURLSTLSCertFile:           "",
+		YOURLSTLSMinVersion:         "1.2",
+		YOURLSTLSServerName:         "",
+		YOURLSTLSInsecureSkipVerify: false,
+
+		HTTPProxy:                 "",
+		HTTPDialTimeout:           defaultHTTPDialTimeout,
+		HTTPTLSHandshakeTimeout:   defaultHTTPTLSHandshakeTimeout,
+		HTTPResponseHeaderTimeout: defaultHTTPResponseHeaderTimeout,
+		HTTPKeepAlive:             defaultHTTPKeepAlive,
+
+		UploadMaxSizeMB:   defaultUploadMaxSizeMB,
+		UploadAllowedMIME: nil,
+		UploadDeniedMIME:  nil,
+		UploadDeniedNames: defaultUploadDeniedNames(),
+		UploadScanSecrets: true,
+		UploadVerifyLink:  false,
+
+		ImageOptimize:     false,
+		ImageMaxDimension: defaultImageMaxDimension,
+		ImageQuality:      defaultImageQuality,
+
+		ReceivePrefix:    "incoming/",
+		ReceiveMaxSizeMB: defaultReceiveMaxSizeMB,
+		ReceiveExpiry:    defaultReceiveExpiry,
+
+		SecretBackend:    "keyring",
+		SecretCommand:    "",
+		SecretSetCommand: "",
+
+		SecretMissingExitCode: 0,
+
+		filePath: "",
+		sources:  nil,
 	}
 }
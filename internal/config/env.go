@@ -47,7 +47,9 @@ func parseEnv(cfg *Config) error {
 		return errors.New("config cannot be nil")
 	}
 
-	opts := env.Options{Prefix: "MINLY_", RequiredIfNoDef: true}
+	clearEnvPointers(cfg)
+
+	opts := env.Options{Prefix: envPrefix, RequiredIfNoDef: true}
 	err := env.ParseWithOptions(cfg, opts)
 	if err != nil {
 		return fmt.Errorf("failed to parse with options: %w", err)
@@ -55,3 +57,10 @@ func parseEnv(cfg *Config) error {
 
 	return nil
 }
+
+// clearEnvPointers resets pointer fields before parsing, env descends into
+// non-nil pointers to structs like url.URL instead of parsing the variable.
+// Their envDefault restores the default if the variable is not set.
+func clearEnvPointers(cfg *Config) {
+	cfg.YOURLSEndpoint = nil
+}
@@ -57,6 +57,12 @@ func FromInput() (*Config, error) {
 		return nil, fmt.Errorf("failed to get YOURLS endpoint: %w", err)
 	}
 
+	var secretBackend, secretCommand, secretSetCommand string
+	secretBackend, secretCommand, secretSetCommand, err = getSecretBackendFromInput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret backend: %w", err)
+	}
+
 	cfg := newDefaultConfig()
 
 	cfg.ProjectName = projectName
@@ -68,6 +74,9 @@ func FromInput() (*Config, error) {
 	cfg.MinioRegion = minioRegion
 	cfg.MinioLinkExpiry = minioLinkExpiry
 	cfg.YOURLSEndpoint = yourlsEndpoint
+	cfg.SecretBackend = secretBackend
+	cfg.SecretCommand = secretCommand
+	cfg.SecretSetCommand = secretSetCommand
 
 	return cfg, nil
 }
@@ -200,6 +209,34 @@ func getYOURLSEndpointFromInput() (*url.URL, error) {
 	return u, nil
 }
 
+func getSecretBackendFromInput() (string, string, string, error) {
+	backend, err := getInput("Enter secret backend (keyring, file, env, command)", "keyring")
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to get secret backend from input: %w", err)
+	}
+
+	if backend != "command" {
+		return backend, "", "", nil
+	}
+
+	var command string
+	command, err = getInput("Enter command to read a secret, {key} is replaced", "pass show minly/{key}")
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to get secret command from input: %w", err)
+	}
+
+	var setCommand string
+	setCommand, err = getInput(
+		"Enter command to store a secret from stdin, leave empty for read-only",
+		"",
+	)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to get secret set command from input: %w", err)
+	}
+
+	return backend, command, setCommand, nil
+}
+
 func getInput(prompt string, def string) (string, error) {
 	if !isTerminal() {
 		return "", errors.New("stdin is not a readable terminal")
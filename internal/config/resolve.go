@@ -0,0 +1,230 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+)
+
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+type Explanation struct {
+	Key    string
+	Env    string
+	Value  string
+	Source Source
+}
+
+func Load(overrides map[string]any) (*Config, error) {
+	// Layers are applied in order: defaults, config file, MINLY_ environment
+	// variables and flag overrides keyed by the JSON name of the config field.
+	cfg := newDefaultConfig()
+	cfg.sources = make(map[string]Source)
+
+	err := loadFileLayer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config file layer: %w", err)
+	}
+
+	err = loadEnvLayer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load environment layer: %w", err)
+	}
+
+	err = loadFlagLayer(cfg, overrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load flag layer: %w", err)
+	}
+
+	err = cfg.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) Explain() []Explanation {
+	var result []Explanation
+
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		key := jsonKey(field)
+		if key == "" {
+			continue
+		}
+
+		source, ok := c.sources[key]
+		if !ok {
+			source = SourceDefault
+		}
+
+		var envName string
+		if tag := field.Tag.Get("env"); tag != "" {
+			envName = envPrefix + tag
+		}
+
+		result = append(result, Explanation{
+			Key:    key,
+			Env:    envName,
+			Value:  formatValue(v.Field(i).Interface()),
+			Source: source,
+		})
+	}
+
+	return result
+}
+
+func loadFileLayer(cfg *Config) error {
+	f, err := openConfigFile()
+	if err != nil {
+		// Running without a stored config is fine, e.g. in CI containers
+		// which only provide environment variables.
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	var raw map[string]json.RawMessage
+	err = json.NewDecoder(f).Decode(&raw)
+	if err != nil {
+		return fmt.Errorf("failed to decode config file: %w", err)
+	}
+
+	// Decode the raw fields one by one so we know which ones the file sets.
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+
+	for i := range t.NumField() {
+		key := jsonKey(t.Field(i))
+
+		value, ok := raw[key]
+		if key == "" || !ok {
+			continue
+		}
+
+		err = json.Unmarshal(value, v.Field(i).Addr().Interface())
+		if err != nil {
+			return fmt.Errorf("failed to decode %s: %w", key, err)
+		}
+
+		cfg.sources[key] = SourceFile
+	}
+
+	cfg.filePath = f.Name()
+
+	return nil
+}
+
+func loadEnvLayer(cfg *Config) error {
+	set := make(map[string]bool)
+
+	envCfg := newDefaultConfig()
+	clearEnvPointers(envCfg)
+
+	opts := env.Options{
+		Prefix: envPrefix,
+		OnSet: func(tag string, _ any, isDefault bool) {
+			if !isDefault {
+				set[strings.TrimPrefix(tag, envPrefix)] = true
+			}
+		},
+	}
+
+	err := env.ParseWithOptions(envCfg, opts)
+	if err != nil {
+		return fmt.Errorf("failed to parse with options: %w", err)
+	}
+
+	src := reflect.ValueOf(envCfg).Elem()
+	dst := reflect.ValueOf(cfg).Elem()
+	t := dst.Type()
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		if !set[field.Tag.Get("env")] {
+			continue
+		}
+
+		dst.Field(i).Set(src.Field(i))
+		cfg.sources[jsonKey(field)] = SourceEnv
+	}
+
+	return nil
+}
+
+func loadFlagLayer(cfg *Config, overrides map[string]any) error {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+
+	for key, value := range overrides {
+		found := false
+
+		for i := range t.NumField() {
+			if jsonKey(t.Field(i)) != key {
+				continue
+			}
+
+			rv := reflect.ValueOf(value)
+			if !rv.IsValid() || !rv.Type().AssignableTo(t.Field(i).Type) {
+				return fmt.Errorf("invalid type %T for %s", value, key)
+			}
+
+			v.Field(i).Set(rv)
+			cfg.sources[key] = SourceFlag
+			found = true
+
+			break
+		}
+
+		if !found {
+			return fmt.Errorf("unknown config key %s", key)
+		}
+	}
+
+	return nil
+}
+
+const envPrefix = "MINLY_"
+
+func formatValue(value any) string {
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+
+	return fmt.Sprint(value)
+}
+
+func jsonKey(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
@@ -0,0 +1,168 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devusSs/minly/internal/config"
+	"github.com/devusSs/minly/internal/paths"
+)
+
+func TestLoadLayers(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		env       map[string]string
+		overrides map[string]any
+		// want maps config keys to their expected value and source.
+		want    map[string]config.Explanation
+		wantErr bool
+	}{
+		{
+			name:      "defaults only",
+			file:      "",
+			env:       nil,
+			overrides: nil,
+			want: map[string]config.Explanation{
+				"minio_bucket_name": {Key: "", Env: "", Value: "minly", Source: config.SourceDefault},
+				"minio_region":      {Key: "", Env: "", Value: "us-east-1", Source: config.SourceDefault},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "file overrides defaults",
+			file:      `{"minio_bucket_name": "from-file", "minio_region": "eu-west-1"}`,
+			env:       nil,
+			overrides: nil,
+			want: map[string]config.Explanation{
+				"minio_bucket_name": {Key: "", Env: "", Value: "from-file", Source: config.SourceFile},
+				"minio_region":      {Key: "", Env: "", Value: "eu-west-1", Source: config.SourceFile},
+				"minio_endpoint":    {Key: "", Env: "", Value: "localhost:9000", Source: config.SourceDefault},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "env overrides file",
+			file:      `{"minio_bucket_name": "from-file", "minio_region": "eu-west-1"}`,
+			env:       map[string]string{"MINLY_MINIO_REGION": "eu-central-1"},
+			overrides: nil,
+			want: map[string]config.Explanation{
+				"minio_bucket_name": {Key: "", Env: "", Value: "from-file", Source: config.SourceFile},
+				"minio_region":      {Key: "", Env: "", Value: "eu-central-1", Source: config.SourceEnv},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "flags override env",
+			file:      `{"minio_bucket_name": "from-file"}`,
+			env:       map[string]string{"MINLY_MINIO_BUCKET_NAME": "from-env"},
+			overrides: map[string]any{"minio_bucket_name": "from-flag"},
+			want: map[string]config.Explanation{
+				"minio_bucket_name": {Key: "", Env: "", Value: "from-flag", Source: config.SourceFlag},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "unknown override",
+			file:      "",
+			env:       nil,
+			overrides: map[string]any{"no_such_key": "value"},
+			want:      nil,
+			wantErr:   true,
+		},
+		{
+			name:      "override with wrong type",
+			file:      "",
+			env:       nil,
+			overrides: map[string]any{"minio_use_ssl": "yes"},
+			want:      nil,
+			wantErr:   true,
+		},
+		{
+			name:      "yourls tls settings with an http endpoint",
+			file:      "",
+			env:       map[string]string{"MINLY_YOURLS_TLS_INSECURE_SKIP_VERIFY": "true"},
+			overrides: nil,
+			want:      nil,
+			wantErr:   true,
+		},
+		{
+			name: "yourls tls settings with an https endpoint",
+			file: "",
+			env: map[string]string{
+				"MINLY_YOURLS_ENDPOINT":                 "https://example.com/yourls-api.php",
+				"MINLY_YOURLS_TLS_INSECURE_SKIP_VERIFY": "true",
+			},
+			overrides: nil,
+			want: map[string]config.Explanation{
+				"yourls_tls_insecure_skip_verify": {Key: "", Env: "", Value: "true", Source: config.SourceEnv},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "invalid value from env",
+			file:      "",
+			env:       map[string]string{"MINLY_MINIO_LINK_EXPIRY": "forever"},
+			overrides: nil,
+			want:      nil,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("MINLY_HOME", home)
+			paths.SetHome("")
+
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			if tt.file != "" {
+				dir := filepath.Join(home, "config")
+
+				err := os.MkdirAll(dir, 0700)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				err = os.WriteFile(filepath.Join(dir, "config.json"), []byte(tt.file), 0600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			c, err := config.Load(tt.overrides)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Load() error = nil, want an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			explained := make(map[string]config.Explanation)
+			for _, e := range c.Explain() {
+				explained[e.Key] = e
+			}
+
+			for key, want := range tt.want {
+				got, ok := explained[key]
+				if !ok {
+					t.Errorf("%s is not explained", key)
+					continue
+				}
+
+				if got.Value != want.Value || got.Source != want.Source {
+					t.Errorf("%s = %s from %s, want %s from %s", key, got.Value, got.Source, want.Value, want.Source)
+				}
+			}
+		})
+	}
+}
@@ -3,7 +3,10 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"path"
+	"regexp"
 	"strings"
 	"time"
 	"unicode"
@@ -25,12 +28,12 @@ func (c *Config) validate() error {
 		return fmt.Errorf("invalid minio endpoint: %w", err)
 	}
 
-	err = validateMinioBucketName(c.MinioBucketName)
+	err = validateMinioBucketName(c.MinioBucketName, c.MinioBucketNameStrict)
 	if err != nil {
 		return fmt.Errorf("invalid minio bucket name: %w", err)
 	}
 
-	err = validateMinioRegion(c.MinioRegion)
+	err = validateMinioRegion(c.MinioRegion, c.MinioRegionPattern)
 	if err != nil {
 		return fmt.Errorf("invalid minio region: %w", err)
 	}
@@ -45,6 +48,91 @@ func (c *Config) validate() error {
 		return fmt.Errorf("invalid yourls endpoint: %w", err)
 	}
 
+	err = validateMinioProvider(c.MinioProvider, c.MinioAddressing, c.MinioSignature)
+	if err != nil {
+		return fmt.Errorf("invalid minio provider: %w", err)
+	}
+
+	err = validateMinioObjectPrefix(c.MinioObjectPrefix)
+	if err != nil {
+		return fmt.Errorf("invalid minio object prefix: %w", err)
+	}
+
+	err = validateMinioLinkMode(c.MinioLinkMode, c.MinioPublicPrefix, c.MinioPublicBaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid minio link mode: %w", err)
+	}
+
+	err = validateMinioCredentials(
+		c.MinioCredentialSource,
+		c.MinioSTSEndpoint,
+		c.MinioWebIdentityTokenFile,
+		c.MinioCredentialDuration,
+	)
+	if err != nil {
+		return fmt.Errorf("invalid minio credentials: %w", err)
+	}
+
+	err = validateTLSMinVersion(c.MinioTLSMinVersion)
+	if err != nil {
+		return fmt.Errorf("invalid minio tls min version: %w", err)
+	}
+
+	err = validateTLSMinVersion(c.YOURLSTLSMinVersion)
+	if err != nil {
+		return fmt.Errorf("invalid yourls tls min version: %w", err)
+	}
+
+	if !c.MinioUseSSL && (c.MinioTLSCAFile != "" || c.MinioTLSCertFile != "" || c.MinioTLSServerName != "" ||
+		c.MinioTLSInsecureSkipVerify) {
+		return errors.New("minio_tls_* settings require minio_use_ssl to be true")
+	}
+
+	if c.YOURLSEndpoint != nil && c.YOURLSEndpoint.Scheme != "https" &&
+		(c.YOURLSTLSCAFile != "" || c.YOURLSTLSCertFile != "" || c.YOURLSTLSServerName != "" ||
+			c.YOURLSTLSInsecureSkipVerify) {
+		return errors.New("yourls_tls_* settings require an https yourls_endpoint")
+	}
+
+	err = validateHTTPProxy(c.HTTPProxy)
+	if err != nil {
+		return fmt.Errorf("invalid http proxy: %w", err)
+	}
+
+	err = validateHTTPTimeouts(
+		c.HTTPDialTimeout,
+		c.HTTPTLSHandshakeTimeout,
+		c.HTTPResponseHeaderTimeout,
+		c.HTTPKeepAlive,
+	)
+	if err != nil {
+		return fmt.Errorf("invalid http timeouts: %w", err)
+	}
+
+	err = validateUploadPolicy(c.UploadMaxSizeMB, c.UploadAllowedMIME, c.UploadDeniedMIME, c.UploadDeniedNames)
+	if err != nil {
+		return fmt.Errorf("invalid upload policy: %w", err)
+	}
+
+	err = validateImageOptimization(c.ImageMaxDimension, c.ImageQuality)
+	if err != nil {
+		return fmt.Errorf("invalid image optimization: %w", err)
+	}
+
+	err = validateReceive(c.ReceivePrefix, c.ReceiveMaxSizeMB, c.ReceiveExpiry)
+	if err != nil {
+		return fmt.Errorf("invalid receive settings: %w", err)
+	}
+
+	err = validateSecretBackend(c.SecretBackend, c.SecretCommand)
+	if err != nil {
+		return fmt.Errorf("invalid secret backend: %w", err)
+	}
+
+	if c.SecretMissingExitCode < 0 || c.SecretMissingExitCode > maxExitCode {
+		return fmt.Errorf("secret_missing_exit_code must be between 0 and %d, got %d", maxExitCode, c.SecretMissingExitCode)
+	}
+
 	return nil
 }
 
@@ -109,11 +197,12 @@ func validateMinioEndpoint(endpoint string) error {
 }
 
 const (
-	minMinioBucketNameLength = 4
-	maxMinioBucketNameLength = 36
+	minMinioBucketNameLength = 3
+	maxMinioBucketNameLength = 63
 )
 
-func validateMinioBucketName(bucketName string) error {
+//nolint:gocognit // Each rule is a separate check so errors can name the one that failed.
+func validateMinioBucketName(bucketName string, strict bool) error {
 	if bucketName == "" {
 		return errors.New("minio_bucket_name cannot be empty")
 	}
@@ -128,39 +217,86 @@ func validateMinioBucketName(bucketName string) error {
 	}
 
 	for _, char := range bucketName {
-		if !unicode.IsLetter(char) {
-			return fmt.Errorf("minio_bucket_name must only contain letters, got '%c'", char)
-		}
-
-		if !unicode.IsLower(char) {
+		if (char < 'a' || char > 'z') && (char < '0' || char > '9') && char != '.' && char != '-' {
 			return fmt.Errorf(
-				"minio_bucket_name must only contain lowercase letters, got '%c'",
+				"minio_bucket_name may only contain lowercase letters, digits, dots and hyphens, got '%c'",
 				char,
 			)
 		}
 	}
 
+	if !isLowerAlnum(bucketName[0]) || !isLowerAlnum(bucketName[len(bucketName)-1]) {
+		return errors.New("minio_bucket_name must begin and end with a lowercase letter or digit")
+	}
+
+	if strings.Contains(bucketName, "..") {
+		return errors.New("minio_bucket_name must not contain two adjacent dots")
+	}
+
+	if net.ParseIP(bucketName) != nil {
+		return errors.New("minio_bucket_name must not be formatted as an IP address")
+	}
+
+	for _, prefix := range reservedBucketPrefixes {
+		if strings.HasPrefix(bucketName, prefix) {
+			return fmt.Errorf("minio_bucket_name must not start with the reserved prefix %s", prefix)
+		}
+	}
+
+	for _, suffix := range reservedBucketSuffixes {
+		if strings.HasSuffix(bucketName, suffix) {
+			return fmt.Errorf("minio_bucket_name must not end with the reserved suffix %s", suffix)
+		}
+	}
+
+	if !strict {
+		return nil
+	}
+
+	// Virtual-host style addressing puts the bucket name into the hostname,
+	// dots break wildcard TLS certificates and labels must be valid DNS labels.
+	if strings.Contains(bucketName, ".") {
+		return errors.New("minio_bucket_name must not contain dots in strict mode (not DNS and TLS compatible)")
+	}
+
+	if strings.Contains(bucketName, "--") {
+		return errors.New("minio_bucket_name must not contain two adjacent hyphens in strict mode")
+	}
+
 	return nil
 }
 
-func validateMinioRegion(region string) error {
+//nolint:gochecknoglobals // Constant lists of reserved names.
+var (
+	reservedBucketPrefixes = []string{"xn--", "sthree-", "amzn-s3-demo-"}
+	reservedBucketSuffixes = []string{"-s3alias", "--ol-s3", ".mrap", "--x-s3", "--table-s3"}
+)
+
+func isLowerAlnum(char byte) bool {
+	return (char >= 'a' && char <= 'z') || (char >= '0' && char <= '9')
+}
+
+const defaultMinioRegionPattern = `^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$`
+
+func validateMinioRegion(region string, pattern string) error {
 	if region == "" {
 		return errors.New("minio_region cannot be empty")
 	}
 
-	switch region {
-	case "us-east-1", "us-east-2", "us-west-1", "us-west-2",
-		"ca-central-1",
-		"eu-west-1", "eu-west-2", "eu-west-3", "eu-central-1", "eu-north-1",
-		"ap-east-1", "ap-south-1", "ap-southeast-1", "ap-southeast-2",
-		"ap-northeast-1", "ap-northeast-2", "ap-northeast-3",
-		"me-south-1", "sa-east-1",
-		"us-gov-west-1", "us-gov-east-1",
-		"cn-north-1", "cn-northwest-1":
-		return nil
-	default:
-		return fmt.Errorf("invalid minio_region: %s", region)
+	if pattern == "" {
+		pattern = defaultMinioRegionPattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("minio_region_pattern %q is not a valid regular expression: %w", pattern, err)
+	}
+
+	if !re.MatchString(region) {
+		return fmt.Errorf("minio_region %q does not match minio_region_pattern %q", region, pattern)
 	}
+
+	return nil
 }
 
 const (
@@ -192,3 +328,277 @@ func validateYOURLSEndpoint(endpoint *url.URL) error {
 
 	return nil
 }
+
+const maxExitCode = 255
+
+func validateSecretBackend(backend string, command string) error {
+	switch backend {
+	case "keyring", "file", "env":
+		return nil
+	case "command":
+		if command == "" {
+			return errors.New("secret_command is required when using the command backend")
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("secret_backend must be one of keyring, file, env or command, got %s", backend)
+	}
+}
+
+func validateMinioProvider(provider string, addressing string, signature string) error {
+	switch provider {
+	case "minio", "aws", "r2", "b2", "wasabi", "custom":
+	default:
+		return fmt.Errorf("minio_provider must be one of minio, aws, r2, b2, wasabi or custom, got %s", provider)
+	}
+
+	switch addressing {
+	case "", "auto", "path", "virtual":
+	default:
+		return fmt.Errorf("minio_addressing must be empty (provider default), auto, path or virtual, got %s", addressing)
+	}
+
+	switch signature {
+	case "", "v4", "v2":
+	default:
+		return fmt.Errorf("minio_signature must be empty (provider default), v4 or v2, got %s", signature)
+	}
+
+	return nil
+}
+
+func validateTLSMinVersion(version string) error {
+	switch version {
+	case "", "1.0", "1.1", "1.2", "1.3":
+		return nil
+	default:
+		return fmt.Errorf("tls min version must be one of 1.0, 1.1, 1.2 or 1.3, got %s", version)
+	}
+}
+
+const maxObjectPrefixLength = 512
+
+func validateMinioObjectPrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+
+	return validateObjectPrefix("minio_object_prefix", prefix)
+}
+
+func validateObjectPrefix(key string, prefix string) error {
+	if len(prefix) > maxObjectPrefixLength {
+		return fmt.Errorf("%s cannot be longer than %d characters", key, maxObjectPrefixLength)
+	}
+
+	if strings.HasPrefix(prefix, "/") || strings.Contains(prefix, "//") {
+		return fmt.Errorf("%s cannot start with a slash or contain empty segments, got %q", key, prefix)
+	}
+
+	for _, segment := range strings.Split(strings.TrimSuffix(prefix, "/"), "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("%s cannot contain . or .. segments, got %q", key, prefix)
+		}
+	}
+
+	for _, char := range prefix {
+		if unicode.IsControl(char) || char == '\\' {
+			return fmt.Errorf("%s contains an invalid character %q", key, char)
+		}
+	}
+
+	return nil
+}
+
+func validateMinioLinkMode(mode string, prefix string, baseURL string) error {
+	switch mode {
+	case "presigned":
+		return nil
+	case "public":
+	default:
+		return fmt.Errorf("minio_link_mode must be presigned or public, got %s", mode)
+	}
+
+	if prefix == "" || strings.HasPrefix(prefix, "/") || strings.Contains(prefix, "..") {
+		return fmt.Errorf("minio_public_prefix must be a relative object prefix like public/, got %q", prefix)
+	}
+
+	// Everything below the prefix becomes readable by anyone,
+	// so it must not be the whole bucket.
+	if strings.Trim(prefix, "/") == "" || strings.Contains(prefix, "*") {
+		return fmt.Errorf("minio_public_prefix cannot match the whole bucket, got %q", prefix)
+	}
+
+	if baseURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("minio_public_base_url is not a valid URL: %w", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("minio_public_base_url must be an http or https URL, got %s", baseURL)
+	}
+
+	return nil
+}
+
+const (
+	minMinioCredentialDuration = 15 * time.Minute
+	maxMinioCredentialDuration = 12 * time.Hour
+)
+
+func validateMinioCredentials(
+	source string,
+	stsEndpoint string,
+	tokenFile string,
+	duration time.Duration,
+) error {
+	switch source {
+	case "static", "env", "file", "iam", "chain":
+		return nil
+	case "assume-role", "web-identity":
+	default:
+		return fmt.Errorf(
+			"minio_credential_source must be one of static, env, file, iam, assume-role, web-identity or chain, got %s",
+			source,
+		)
+	}
+
+	// STS based sources need an endpoint to exchange credentials with.
+	u, err := url.Parse(stsEndpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("minio_sts_endpoint must be an http or https URL for %s, got %q", source, stsEndpoint)
+	}
+
+	if source == "web-identity" && tokenFile == "" {
+		return errors.New("minio_web_identity_token_file cannot be empty for web-identity")
+	}
+
+	if duration < minMinioCredentialDuration || duration > maxMinioCredentialDuration {
+		return fmt.Errorf(
+			"minio_credential_duration must be between %s and %s, got %s",
+			minMinioCredentialDuration,
+			maxMinioCredentialDuration,
+			duration,
+		)
+	}
+
+	return nil
+}
+
+func validateHTTPProxy(proxy string) error {
+	if proxy == "" {
+		return nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return fmt.Errorf("http_proxy is not a valid URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("http_proxy must use http, https or socks5, got %s", u.Scheme)
+	}
+
+	if u.Host == "" {
+		return errors.New("http_proxy must contain a host")
+	}
+
+	return nil
+}
+
+func validateHTTPTimeouts(timeouts ...time.Duration) error {
+	for _, timeout := range timeouts {
+		if timeout <= 0 {
+			return fmt.Errorf("http timeouts must be positive durations, got %s", timeout)
+		}
+	}
+
+	return nil
+}
+
+func validateUploadPolicy(maxSizeMB int64, allowedMIME []string, deniedMIME []string, deniedNames []string) error {
+	if maxSizeMB < 0 {
+		return fmt.Errorf("upload_max_size_mb cannot be negative (0 disables the limit), got %d", maxSizeMB)
+	}
+
+	lists := map[string][]string{
+		"upload_allowed_mime": allowedMIME,
+		"upload_denied_mime":  deniedMIME,
+		"upload_denied_names": deniedNames,
+	}
+
+	for name, patterns := range lists {
+		for _, pattern := range patterns {
+			if pattern == "" {
+				return fmt.Errorf("%s cannot contain empty patterns", name)
+			}
+
+			_, err := path.Match(pattern, "")
+			if err != nil {
+				return fmt.Errorf("%s contains an invalid pattern %s: %w", name, pattern, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+const (
+	minReceiveExpiry = 1 * time.Minute
+	maxReceiveExpiry = 7 * 24 * time.Hour
+)
+
+func validateReceive(prefix string, maxSizeMB int64, expiry time.Duration) error {
+	if prefix == "" {
+		return errors.New("receive_prefix cannot be empty, uploads must not be allowed for the whole bucket")
+	}
+
+	err := validateObjectPrefix("receive_prefix", prefix)
+	if err != nil {
+		return err
+	}
+
+	if maxSizeMB <= 0 {
+		return fmt.Errorf("receive_max_size_mb must be positive, got %d", maxSizeMB)
+	}
+
+	if expiry < minReceiveExpiry || expiry > maxReceiveExpiry {
+		return fmt.Errorf(
+			"receive_expiry must be between %s and %s, got %s",
+			minReceiveExpiry,
+			maxReceiveExpiry,
+			expiry,
+		)
+	}
+
+	return nil
+}
+
+const (
+	minImageQuality = 1
+	maxImageQuality = 100
+)
+
+func validateImageOptimization(maxDimension int, quality int) error {
+	if maxDimension < 0 {
+		return fmt.Errorf("image_max_dimension cannot be negative (0 keeps the size), got %d", maxDimension)
+	}
+
+	if quality < minImageQuality || quality > maxImageQuality {
+		return fmt.Errorf(
+			"image_quality must be between %d and %d, got %d",
+			minImageQuality,
+			maxImageQuality,
+			quality,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+type Check struct {
+	Name string
+	Hint string
+	// Needs names earlier checks this one reads results from. It is skipped
+	// if one of them timed out, since that check may still be running.
+	Needs []string
+	Run   func(ctx context.Context) (Status, string)
+}
+
+type Result struct {
+	Name     string        `json:"name"`
+	Status   Status        `json:"status"`
+	Message  string        `json:"message"`
+	Hint     string        `json:"hint,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+func Run(ctx context.Context, checks []Check, timeout time.Duration) []Result {
+	results := make([]Result, 0, len(checks))
+	abandoned := make(map[string]bool)
+
+	for _, check := range checks {
+		if need := abandonedNeed(check, abandoned); need != "" {
+			abandoned[check.Name] = true
+			results = append(results, Result{
+				Name:     check.Name,
+				Status:   StatusWarn,
+				Message:  fmt.Sprintf("skipped: %s did not finish", need),
+				Hint:     check.Hint,
+				Duration: 0,
+			})
+
+			continue
+		}
+
+		result, finished := runCheck(ctx, check, timeout)
+		if !finished {
+			abandoned[check.Name] = true
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func Failed(results []Result) int {
+	failed := 0
+
+	for _, r := range results {
+		if r.Status == StatusFail {
+			failed++
+		}
+	}
+
+	return failed
+}
+
+func WriteTable(w io.Writer, results []Result) error {
+	table := tablewriter.NewWriter(w)
+	table.Header([]string{"Check", "Status", "Message", "Hint"})
+
+	for _, r := range results {
+		hint := ""
+		if r.Status != StatusPass {
+			hint = r.Hint
+		}
+
+		err := table.Append([]string{r.Name, string(r.Status), r.Message, hint})
+		if err != nil {
+			return fmt.Errorf("failed to append row to table: %w", err)
+		}
+	}
+
+	err := table.Render()
+	if err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+
+	return nil
+}
+
+func WriteJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	err := enc.Encode(results)
+	if err != nil {
+		return fmt.Errorf("failed to encode results: %w", err)
+	}
+
+	return nil
+}
+
+func abandonedNeed(check Check, abandoned map[string]bool) string {
+	for _, need := range check.Needs {
+		if abandoned[need] {
+			return need
+		}
+	}
+
+	return ""
+}
+
+// runCheck runs check and reports whether it finished, a check that timed
+// out keeps running in the background.
+func runCheck(ctx context.Context, check Check, timeout time.Duration) (Result, bool) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		status  Status
+		message string
+	}
+
+	// Some checks call into libraries which do not honor the context,
+	// so the timeout is enforced here as well.
+	done := make(chan outcome, 1)
+	start := time.Now()
+
+	go func() {
+		status, message := check.Run(ctx)
+		done <- outcome{status: status, message: message}
+	}()
+
+	var o outcome
+	finished := true
+	select {
+	case o = <-done:
+	case <-ctx.Done():
+		o = outcome{status: StatusFail, message: fmt.Sprintf("timed out after %s", timeout)}
+		finished = false
+	}
+
+	return Result{
+		Name:     check.Name,
+		Status:   o.status,
+		Message:  o.message,
+		Hint:     check.Hint,
+		Duration: time.Since(start),
+	}, finished
+}
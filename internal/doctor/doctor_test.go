@@ -0,0 +1,83 @@
+package doctor_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devusSs/minly/internal/doctor"
+)
+
+func TestRunSkipsChecksNeedingTimedOutChecks(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	var value string
+
+	checks := []doctor.Check{
+		{
+			Name:  "slow",
+			Hint:  "",
+			Needs: nil,
+			Run: func(_ context.Context) (doctor.Status, string) {
+				// Ignores the context like libraries without context support.
+				<-release
+				value = "late"
+
+				return doctor.StatusPass, ""
+			},
+		},
+		{
+			Name:  "reader",
+			Hint:  "",
+			Needs: []string{"slow"},
+			Run: func(_ context.Context) (doctor.Status, string) {
+				return doctor.StatusPass, value
+			},
+		},
+		{
+			Name:  "transitive",
+			Hint:  "",
+			Needs: []string{"reader"},
+			Run: func(_ context.Context) (doctor.Status, string) {
+				return doctor.StatusPass, value
+			},
+		},
+		{
+			Name:  "independent",
+			Hint:  "",
+			Needs: nil,
+			Run: func(_ context.Context) (doctor.Status, string) {
+				return doctor.StatusPass, "ok"
+			},
+		},
+	}
+
+	results := doctor.Run(context.Background(), checks, 10*time.Millisecond)
+
+	want := []struct {
+		status  doctor.Status
+		message string
+	}{
+		{status: doctor.StatusFail, message: "timed out"},
+		{status: doctor.StatusWarn, message: "skipped: slow"},
+		{status: doctor.StatusWarn, message: "skipped: reader"},
+		{status: doctor.StatusPass, message: "ok"},
+	}
+
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+
+	for i, w := range want {
+		r := results[i]
+		if r.Status != w.status || !strings.HasPrefix(r.Message, w.message) {
+			t.Errorf("%s = %s %q, want %s %q...", r.Name, r.Status, r.Message, w.status, w.message)
+		}
+	}
+
+	if doctor.Failed(results) != 1 {
+		t.Errorf("Failed() = %d, want 1", doctor.Failed(results))
+	}
+}
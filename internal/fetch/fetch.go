@@ -0,0 +1,175 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// partSuffix is appended to the path while downloading,
+// an existing part file is continued with a range request.
+const partSuffix = ".part"
+
+// File downloads rawURL to path and returns the response headers.
+func File(ctx context.Context, client *http.Client, rawURL string, path string) (http.Header, error) {
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	if rawURL == "" || path == "" {
+		return nil, errors.New("URL and path cannot be empty")
+	}
+
+	part := path + partSuffix
+
+	var offset int64
+	info, err := os.Stat(part)
+	if err == nil {
+		offset = info.Size()
+	}
+
+	var resp *http.Response
+	resp, err = get(ctx, client, rawURL, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && rangeStart(resp) == offset:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0 && rangeTotal(resp) == offset:
+		// The part file already holds the whole object.
+		return resp.Header, finish(part, path)
+	case resp.StatusCode == http.StatusOK:
+		flags |= os.O_TRUNC
+	case offset > 0 && (resp.StatusCode == http.StatusPartialContent ||
+		resp.StatusCode == http.StatusRequestedRangeNotSatisfiable):
+		// The part file does not match the object, e.g. because it was
+		// replaced in the meantime, start over without a range.
+		_ = resp.Body.Close()
+
+		err = os.Remove(part)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove stale part file: %w", err)
+		}
+
+		return File(ctx, client, rawURL, path)
+	default:
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var f *os.File
+	f, err = os.OpenFile(part, flags, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open part file: %w", err)
+	}
+
+	_, err = io.Copy(f, resp.Body)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to download, run again to resume: %w", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to close part file: %w", err)
+	}
+
+	return resp.Header, finish(part, path)
+}
+
+// Stream writes the response body of rawURL to w.
+func Stream(ctx context.Context, client *http.Client, rawURL string, w io.Writer) (http.Header, error) {
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	resp, err := get(ctx, client, rawURL, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download: %w", err)
+	}
+
+	return resp.Header, nil
+}
+
+func get(ctx context.Context, client *http.Client, rawURL string, offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	var resp *http.Response
+	resp, err = client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return resp, nil
+}
+
+// rangeStart returns the first byte of a Content-Range header like
+// "bytes 100-199/200", or -1 if it cannot be parsed.
+func rangeStart(resp *http.Response) int64 {
+	r, ok := strings.CutPrefix(resp.Header.Get("Content-Range"), "bytes ")
+	if !ok {
+		return -1
+	}
+
+	start, _, ok := strings.Cut(r, "-")
+	if !ok {
+		return -1
+	}
+
+	n, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
+
+// rangeTotal returns the object size of a Content-Range header like
+// "bytes */200", or -1 if it cannot be parsed or is unknown.
+func rangeTotal(resp *http.Response) int64 {
+	_, total, ok := strings.Cut(resp.Header.Get("Content-Range"), "/")
+	if !ok {
+		return -1
+	}
+
+	n, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
+
+func finish(part string, path string) error {
+	err := os.Rename(part, path)
+	if err != nil {
+		return fmt.Errorf("failed to move part file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package fetch_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/devusSs/minly/internal/fetch"
+)
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+
+	serve := func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "object", time.Time{}, bytes.NewReader(content))
+	}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		// part is the content of an existing part file, nil for none.
+		part      []byte
+		wantRange string
+		wantErr   bool
+	}{
+		{
+			name:      "fresh download",
+			handler:   serve,
+			part:      nil,
+			wantRange: "",
+			wantErr:   false,
+		},
+		{
+			name:      "resume",
+			handler:   serve,
+			part:      content[:10],
+			wantRange: "bytes=10-",
+			wantErr:   false,
+		},
+		{
+			name:      "part file holds the whole object",
+			handler:   serve,
+			part:      content,
+			wantRange: "bytes=36-",
+			wantErr:   false,
+		},
+		{
+			name:      "part file is larger than the object",
+			handler:   serve,
+			part:      append(bytes.Clone(content), "stale"...),
+			wantRange: "",
+			wantErr:   false,
+		},
+		{
+			name: "server ignores the range",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write(content)
+			},
+			part:      []byte("0123"),
+			wantRange: "bytes=4-",
+			wantErr:   false,
+		},
+		{
+			name: "server answers with another range",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Range") == "" {
+					_, _ = w.Write(content)
+					return
+				}
+
+				w.Header().Set("Content-Range", "bytes 0-35/"+strconv.Itoa(len(content)))
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write(content)
+			},
+			part:      []byte("0123"),
+			wantRange: "",
+			wantErr:   false,
+		},
+		{
+			name: "missing object",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			part:      nil,
+			wantRange: "",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// lastRange is the Range header of the last request.
+			var lastRange string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lastRange = r.Header.Get("Range")
+				tt.handler(w, r)
+			}))
+			t.Cleanup(srv.Close)
+
+			path := filepath.Join(t.TempDir(), "object")
+
+			if tt.part != nil {
+				err := os.WriteFile(path+".part", tt.part, 0600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := fetch.File(context.Background(), srv.Client(), srv.URL, path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("File() error = nil, want an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("File() error = %v", err)
+			}
+
+			if lastRange != tt.wantRange {
+				t.Errorf("last Range = %q, want %q", lastRange, tt.wantRange)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got, content) {
+				t.Errorf("downloaded %q, want %q", got, content)
+			}
+
+			if _, err = os.Stat(path + ".part"); err == nil {
+				t.Error("part file was not removed")
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package highlight
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+const styleName = "github"
+
+// Lexer returns the lexer for a language name or alias like "go" or "py".
+// Without a name the language is guessed from the text.
+func Lexer(lang string, text string) (chroma.Lexer, error) {
+	if lang == "" {
+		l := lexers.Analyse(text)
+		if l == nil {
+			l = lexers.Fallback
+		}
+
+		return l, nil
+	}
+
+	l := lexers.Get(lang)
+	if l == nil {
+		return nil, fmt.Errorf("unknown language %s", lang)
+	}
+
+	return l, nil
+}
+
+// Language returns the canonical name of a language name or alias.
+func Language(lang string) (string, error) {
+	l, err := Lexer(lang, "")
+	if err != nil {
+		return "", err
+	}
+
+	return l.Config().Name, nil
+}
+
+// HTML renders text as a standalone, syntax-highlighted HTML page with line numbers.
+func HTML(text string, lang string, title string) ([]byte, error) {
+	if text == "" {
+		return nil, errors.New("text cannot be empty")
+	}
+
+	l, err := Lexer(lang, text)
+	if err != nil {
+		return nil, err
+	}
+
+	var it chroma.Iterator
+	it, err = chroma.Coalesce(l).Tokenise(nil, text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tokenise text: %w", err)
+	}
+
+	style := styles.Get(styleName)
+	f := chromahtml.New(
+		chromahtml.WithClasses(true),
+		chromahtml.WithLineNumbers(true),
+		chromahtml.WithLinkableLineNumbers(true, "L"),
+	)
+
+	var css, body bytes.Buffer
+	err = f.WriteCSS(&css, style)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write CSS: %w", err)
+	}
+
+	err = f.Format(&body, style, it)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format text: %w", err)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, pageTemplate, html.EscapeString(title), css.String(), body.String())
+
+	return b.Bytes(), nil
+}
+
+// The chroma standalone page has no doctype and charset, non-ASCII
+// text would be rendered wrongly without them.
+const pageTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>%s</title>
+<style>
+body { margin: 0; }
+%s</style>
+</head>
+<body class="bg">
+%s</body>
+</html>
+`
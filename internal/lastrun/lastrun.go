@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/devusSs/minly/internal/paths"
 )
 
 type LastRun struct {
@@ -90,14 +92,7 @@ func openLastRunFile() (*os.File, error) {
 }
 
 func setupLastRunDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get user home directory: %w", err)
-	}
-
-	lastRunDir := filepath.Join(home, ".minly", "lastrun")
-
-	err = os.MkdirAll(lastRunDir, 0700)
+	lastRunDir, err := paths.StateDir("lastrun")
 	if err != nil {
 		return "", fmt.Errorf("failed to create last run directory: %w", err)
 	}
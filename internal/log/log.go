@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/rs/zerolog"
+
+	"github.com/devusSs/minly/internal/paths"
 )
 
 func Setup() error {
@@ -180,14 +182,7 @@ func createFile() (*os.File, error) {
 }
 
 func setupLogsDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get user home directory: %w", err)
-	}
-
-	logsDir := filepath.Join(home, ".minly", "logs")
-
-	err = os.MkdirAll(logsDir, 0700)
+	logsDir, err := paths.LogsDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to create logs directory: %w", err)
 	}
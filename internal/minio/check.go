@@ -0,0 +1,79 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+)
+
+func (c *Client) CheckBucket(ctx context.Context) (bool, error) {
+	if !c.setup {
+		return false, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return false, errors.New("context cannot be nil")
+	}
+
+	// BucketExists fails with an access denied error for invalid credentials,
+	// so this doubles as an authentication check.
+	exists, err := c.minioClient.BucketExists(ctx, c.bucketName)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if bucket exists: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (c *Client) BucketRegion(ctx context.Context) (string, error) {
+	if !c.setup {
+		return "", errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return "", errors.New("context cannot be nil")
+	}
+
+	region, err := c.minioClient.GetBucketLocation(ctx, c.bucketName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bucket location: %w", err)
+	}
+
+	return region, nil
+}
+
+func (c *Client) CheckWrite(ctx context.Context) error {
+	if !c.setup {
+		return errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+
+	objectName := ".minly-doctor-" + uuid.NewString()
+	content := []byte("minly doctor write check")
+
+	_, err := c.minioClient.PutObject(
+		ctx,
+		c.bucketName,
+		objectName,
+		bytes.NewReader(content),
+		int64(len(content)),
+		minio.PutObjectOptions{ContentType: "text/plain"},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to put probe object: %w", err)
+	}
+
+	err = c.minioClient.RemoveObject(ctx, c.bucketName, objectName, minio.RemoveObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to remove probe object %s: %w", objectName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package minio
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+
+	"github.com/minio/minio-go/v7"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// compositeMode checksums of multipart uploads are checksums of the part
+// checksums, they cannot be compared with a checksum of the whole data.
+const compositeMode = "COMPOSITE"
+
+// checksum hashes the uploaded data locally. The SHA-256 is recorded in
+// the history, the CRC32C is compared with the full object checksum the
+// server computed.
+type checksum struct {
+	sha256 hash.Hash
+	crc32c hash.Hash32
+	size   int64
+}
+
+func newChecksum() *checksum {
+	return &checksum{
+		sha256: sha256.New(),
+		crc32c: crc32.New(crc32.MakeTable(crc32.Castagnoli)),
+		size:   0,
+	}
+}
+
+func (s *checksum) Write(p []byte) (int, error) {
+	s.sha256.Write(p)
+	s.crc32c.Write(p)
+	s.size += int64(len(p))
+
+	return len(p), nil
+}
+
+func (s *checksum) SHA256() string {
+	return hex.EncodeToString(s.sha256.Sum(nil))
+}
+
+func (s *checksum) CRC32C() string {
+	return base64.StdEncoding.EncodeToString(s.crc32c.Sum(nil))
+}
+
+// checksumType is the checksum minio-go sends with uploads, providers
+// without support for it only get the size compared.
+func (c *Client) checksumType() minio.ChecksumType {
+	if !c.provider.Supports(FeatureChecksum) || c.provider.Signature != SignatureV4 {
+		return minio.ChecksumNone
+	}
+
+	return minio.ChecksumFullObjectCRC32C
+}
+
+// verify compares what the server reported for an upload with the local
+// checksum and removes the uploaded object or version if they differ.
+func (c *Client) verify(ctx context.Context, key string, info minio.UploadInfo, sum *checksum) error {
+	var err error
+
+	switch {
+	case info.Size != sum.size:
+		err = fmt.Errorf("%w: uploaded %d bytes but server stored %d", ErrChecksumMismatch, sum.size, info.Size)
+	case info.ChecksumCRC32C != "" && info.ChecksumMode != compositeMode && info.ChecksumCRC32C != sum.CRC32C():
+		err = fmt.Errorf(
+			"%w: local CRC32C %s but server reported %s",
+			ErrChecksumMismatch,
+			sum.CRC32C(),
+			info.ChecksumCRC32C,
+		)
+	default:
+		return nil
+	}
+
+	// Use a fresh context so an interrupted upload is still cleaned up. On
+	// versioned buckets only the new version is removed, removing the key
+	// would hide the earlier versions behind a delete marker.
+	rmErr := c.minioClient.RemoveObject(context.WithoutCancel(ctx), c.bucketName, key, minio.RemoveObjectOptions{
+		VersionID: info.VersionID,
+	})
+	if rmErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to remove corrupt object %s: %w", key, rmErr))
+	}
+
+	return err
+}
@@ -0,0 +1,98 @@
+package minio_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash/crc32"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devusSs/minly/internal/minio"
+)
+
+func TestUploadFileVerifiesChecksum(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("report content")
+
+	crc := binary.BigEndian.AppendUint32(nil, crc32.Checksum(content, crc32.MakeTable(crc32.Castagnoli)))
+	sum := sha256.Sum256(content)
+
+	tests := []struct {
+		name       string
+		put        http.Header
+		wantErr    error
+		wantDelete bool
+	}{
+		{
+			name:       "matching checksum",
+			put:        http.Header{"X-Amz-Checksum-Crc32c": {base64.StdEncoding.EncodeToString(crc)}},
+			wantErr:    nil,
+			wantDelete: false,
+		},
+		{
+			name:       "no server checksum",
+			put:        http.Header{},
+			wantErr:    nil,
+			wantDelete: false,
+		},
+		{
+			name: "composite checksum is not compared",
+			put: http.Header{
+				"X-Amz-Checksum-Crc32c": {"AAAAAA=="},
+				"X-Amz-Checksum-Type":   {"COMPOSITE"},
+			},
+			wantErr:    nil,
+			wantDelete: false,
+		},
+		{
+			name:       "mismatching checksum",
+			put:        http.Header{"X-Amz-Checksum-Crc32c": {"AAAAAA=="}},
+			wantErr:    minio.ErrChecksumMismatch,
+			wantDelete: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := newFakeS3(tt.put, "")
+			c := newTestClient(t, s)
+
+			path := filepath.Join(t.TempDir(), "report.txt")
+
+			err := os.WriteFile(path, content, 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			obj, err := c.UploadFile(context.Background(), path, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UploadFile() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := len(s.requestsWith("DELETE ")) > 0; got != tt.wantDelete {
+				t.Errorf("object deleted = %v, want %v", got, tt.wantDelete)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if obj.SHA256 != hex.EncodeToString(sum[:]) {
+				t.Errorf("SHA256 = %s, want %s", obj.SHA256, hex.EncodeToString(sum[:]))
+			}
+
+			if obj.Size != int64(len(content)) {
+				t.Errorf("Size = %d, want %d", obj.Size, len(content))
+			}
+		})
+	}
+}
@@ -4,45 +4,59 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
 )
 
 type Client struct {
 	minioClient *minio.Client
+	provider    Provider
 
 	setup        bool
 	bucketName   string
 	bucketRegion string
 	linkExpiry   time.Duration
+	prefix       string
+	tags         map[string]string
+	metadata     map[string]string
+
+	public        bool
+	publicPolicy  bool
+	publicPrefix  string
+	publicBaseURL *url.URL
 }
 
-func NewClient(
-	endpoint string,
-	accessKey string,
-	accessSecret string,
-	useSSL bool,
-	region string,
-) (*Client, error) {
-	if endpoint == "" {
-		return nil, errors.New("endpoint cannot be empty")
-	}
+type Options struct {
+	Endpoint    string
+	Credentials Credentials
+	UseSSL      bool
+	Region      string
+	Provider    Provider
+	Transport   http.RoundTripper
+}
 
-	if accessKey == "" {
-		return nil, errors.New("access key cannot be empty")
+func NewClient(opts Options) (*Client, error) {
+	if opts.Endpoint == "" {
+		return nil, errors.New("endpoint cannot be empty")
 	}
 
-	if accessSecret == "" {
-		return nil, errors.New("access secret cannot be empty")
+	creds, err := newCredentials(opts.Credentials, opts.Provider.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credentials: %w", err)
 	}
 
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, accessSecret, ""),
-		Secure: useSSL,
-		Region: region,
+	var client *minio.Client
+	client, err = minio.New(opts.Endpoint, &minio.Options{
+		Creds:        creds,
+		Secure:       opts.UseSSL,
+		Transport:    opts.Transport,
+		Region:       opts.Provider.Region(opts.Endpoint, opts.Region),
+		BucketLookup: opts.Provider.bucketLookup(),
+		// Needed to send the checksums that uploads are verified with.
+		TrailingHeaders: opts.Provider.Supports(FeatureChecksum),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create MinIO client: %w", err)
@@ -50,10 +64,19 @@ func NewClient(
 
 	return &Client{
 		minioClient:  client,
+		provider:     opts.Provider,
 		setup:        false,
 		bucketName:   "",
 		bucketRegion: "",
 		linkExpiry:   0,
+		prefix:       "",
+		tags:         nil,
+		metadata:     nil,
+
+		public:        false,
+		publicPolicy:  false,
+		publicPrefix:  "",
+		publicBaseURL: nil,
 	}, nil
 }
 
@@ -71,13 +94,32 @@ func (c *Client) Setup(bucketName string, bucketRegion string, linkExpiry time.D
 	}
 
 	c.bucketName = bucketName
-	c.bucketRegion = bucketRegion
+	c.bucketRegion = c.provider.Region(c.minioClient.EndpointURL().Host, bucketRegion)
 	c.linkExpiry = linkExpiry
 	c.setup = true
 
 	return nil
 }
 
+// SetLinkExpiry changes how long the links of following uploads are valid.
+func (c *Client) SetLinkExpiry(linkExpiry time.Duration) error {
+	if !c.setup {
+		return errors.New("client is not set up")
+	}
+
+	if linkExpiry <= 0 {
+		return errors.New("link expiry has to be positive")
+	}
+
+	c.linkExpiry = linkExpiry
+
+	return nil
+}
+
+func (c *Client) Provider() Provider {
+	return c.provider
+}
+
 func (c *Client) createBucketIfNotExists(ctx context.Context) error {
 	if ctx == nil {
 		return errors.New("context cannot be nil")
@@ -0,0 +1,141 @@
+package minio_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devusSs/minly/internal/minio"
+)
+
+const testBucket = "minly"
+
+// fakeS3 is a bucket with versioning enabled that answers every object
+// upload with the headers in put and records all requests.
+type fakeS3 struct {
+	// put are extra headers of upload responses, e.g. a version ID.
+	put http.Header
+
+	mu       sync.Mutex
+	requests []string
+	// policy is the bucket policy, empty if the bucket has none.
+	policy string
+}
+
+// handle answers the requests minly sends around uploads.
+func (s *fakeS3) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+
+	key := strings.TrimPrefix(r.URL.Path, "/"+testBucket+"/")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Has("policy"):
+		if s.policy == "" {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `<Error><Code>NoSuchBucketPolicy</Code></Error>`)
+
+			return
+		}
+
+		_, _ = io.WriteString(w, s.policy)
+	case r.Method == http.MethodPut && r.URL.Query().Has("policy"):
+		s.policy = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Query().Has("versioning"):
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = io.WriteString(w, `<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>`)
+	case r.Method == http.MethodPut && key != r.URL.Path:
+		for name, values := range s.put {
+			w.Header()[name] = values
+		}
+
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodDelete:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// requestsWith returns the recorded requests starting with prefix, e.g.
+// "DELETE ".
+func (s *fakeS3) requestsWith(prefix string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var requests []string
+	for _, r := range s.requests {
+		if strings.HasPrefix(r, prefix) {
+			requests = append(requests, r)
+		}
+	}
+
+	return requests
+}
+
+func newFakeS3(put http.Header, policy string) *fakeS3 {
+	return &fakeS3{put: put, mu: sync.Mutex{}, requests: nil, policy: policy}
+}
+
+func newTestClient(t *testing.T, s *fakeS3) *minio.Client {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := minio.NewProvider(minio.ProviderMinIO, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := minio.NewClient(minio.Options{
+		Endpoint: u.Host,
+		Credentials: minio.Credentials{
+			Source:               minio.CredentialsStatic,
+			AccessKey:            "access",
+			AccessSecret:         "secret",
+			File:                 "",
+			Profile:              "",
+			STSEndpoint:          "",
+			RoleARN:              "",
+			RoleSessionName:      "",
+			WebIdentityTokenFile: "",
+			Duration:             0,
+		},
+		UseSSL:    false,
+		Region:    "us-east-1",
+		Provider:  provider,
+		Transport: nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Setup(testBucket, "us-east-1", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func (s *fakeS3) currentPolicy() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.policy
+}
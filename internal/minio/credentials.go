@@ -0,0 +1,192 @@
+package minio
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type CredentialSource string
+
+const (
+	// CredentialsStatic uses the access key and secret from the secret backend.
+	CredentialsStatic CredentialSource = "static"
+	// CredentialsEnv reads AWS_ACCESS_KEY_ID and friends or MINIO_ROOT_USER and friends.
+	CredentialsEnv CredentialSource = "env"
+	// CredentialsFile reads a profile of an AWS-style credentials file.
+	CredentialsFile CredentialSource = "file"
+	// CredentialsIAM uses the EC2, ECS or EKS instance metadata service.
+	CredentialsIAM CredentialSource = "iam"
+	// CredentialsAssumeRole exchanges the static keys for temporary ones via STS AssumeRole.
+	CredentialsAssumeRole CredentialSource = "assume-role"
+	// CredentialsWebIdentity exchanges a token file via STS AssumeRoleWithWebIdentity.
+	CredentialsWebIdentity CredentialSource = "web-identity"
+	// CredentialsChain tries env, file and IAM in that order.
+	CredentialsChain CredentialSource = "chain"
+)
+
+type Credentials struct {
+	Source CredentialSource
+
+	// Used by the static and assume-role sources.
+	AccessKey    string
+	AccessSecret string
+
+	// Used by the file source, empty values fall back to
+	// AWS_SHARED_CREDENTIALS_FILE, AWS_PROFILE and the AWS defaults.
+	File    string
+	Profile string
+
+	// Used by the assume-role and web-identity sources.
+	STSEndpoint          string
+	RoleARN              string
+	RoleSessionName      string
+	WebIdentityTokenFile string
+	Duration             time.Duration
+}
+
+func CredentialSources() []CredentialSource {
+	return []CredentialSource{
+		CredentialsStatic,
+		CredentialsEnv,
+		CredentialsFile,
+		CredentialsIAM,
+		CredentialsAssumeRole,
+		CredentialsWebIdentity,
+		CredentialsChain,
+	}
+}
+
+func ParseCredentialSource(s string) (CredentialSource, error) {
+	for _, source := range CredentialSources() {
+		if CredentialSource(s) == source {
+			return source, nil
+		}
+	}
+
+	names := make([]string, 0, len(CredentialSources()))
+	for _, source := range CredentialSources() {
+		names = append(names, string(source))
+	}
+
+	return "", fmt.Errorf("unknown credential source %s, must be one of %s", s, strings.Join(names, ", "))
+}
+
+// NeedsKeys reports whether the source requires the access key and secret
+// stored in the secret backend.
+func (s CredentialSource) NeedsKeys() bool {
+	return s == CredentialsStatic || s == CredentialsAssumeRole
+}
+
+func newCredentials(c Credentials, signature string) (*credentials.Credentials, error) {
+	source := c.Source
+	if source == "" {
+		source = CredentialsStatic
+	}
+
+	if source.NeedsKeys() {
+		if c.AccessKey == "" {
+			return nil, errors.New("access key cannot be empty")
+		}
+
+		if c.AccessSecret == "" {
+			return nil, errors.New("access secret cannot be empty")
+		}
+	}
+
+	// All non-static sources refresh themselves once the temporary
+	// credentials are about to expire, minio-go handles that for us.
+	switch source {
+	case CredentialsStatic:
+		if signature == SignatureV2 {
+			return credentials.NewStaticV2(c.AccessKey, c.AccessSecret, ""), nil
+		}
+
+		return credentials.NewStaticV4(c.AccessKey, c.AccessSecret, ""), nil
+	case CredentialsEnv:
+		return credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+		}), nil
+	case CredentialsFile:
+		return credentials.NewFileAWSCredentials(c.File, c.Profile), nil
+	case CredentialsIAM:
+		return credentials.NewIAM(""), nil
+	case CredentialsAssumeRole:
+		return newAssumeRoleCredentials(c)
+	case CredentialsWebIdentity:
+		return newWebIdentityCredentials(c)
+	case CredentialsChain:
+		return credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{},
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown credential source %s", source)
+	}
+}
+
+func newAssumeRoleCredentials(c Credentials) (*credentials.Credentials, error) {
+	if c.STSEndpoint == "" {
+		return nil, errors.New("STS endpoint cannot be empty for assume-role credentials")
+	}
+
+	creds, err := credentials.NewSTSAssumeRole(c.STSEndpoint, credentials.STSAssumeRoleOptions{
+		AccessKey:       c.AccessKey,
+		SecretKey:       c.AccessSecret,
+		SessionToken:    "",
+		Policy:          "",
+		Location:        "",
+		DurationSeconds: int(c.Duration.Seconds()),
+		RoleARN:         c.RoleARN,
+		RoleSessionName: c.RoleSessionName,
+		ExternalID:      "",
+		TokenRevokeType: "",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create assume-role credentials: %w", err)
+	}
+
+	return creds, nil
+}
+
+func newWebIdentityCredentials(c Credentials) (*credentials.Credentials, error) {
+	if c.STSEndpoint == "" {
+		return nil, errors.New("STS endpoint cannot be empty for web-identity credentials")
+	}
+
+	if c.WebIdentityTokenFile == "" {
+		return nil, errors.New("token file cannot be empty for web-identity credentials")
+	}
+
+	// The token file is read on every refresh because
+	// CI systems and Kubernetes rotate it regularly.
+	getToken := func() (*credentials.WebIdentityToken, error) {
+		token, err := os.ReadFile(c.WebIdentityTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read web identity token file: %w", err)
+		}
+
+		return &credentials.WebIdentityToken{
+			Token:        strings.TrimSpace(string(token)),
+			AccessToken:  "",
+			RefreshToken: "",
+			Expiry:       int(c.Duration.Seconds()),
+		}, nil
+	}
+
+	creds, err := credentials.NewSTSWebIdentity(c.STSEndpoint, getToken, func(i *credentials.STSWebIdentity) {
+		i.RoleARN = c.RoleARN
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create web-identity credentials: %w", err)
+	}
+
+	return creds, nil
+}
@@ -0,0 +1,106 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func (c *Client) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	if key == "" {
+		return nil, errors.New("key cannot be empty")
+	}
+
+	info, err := c.minioClient.StatObject(ctx, c.bucketName, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", key, err)
+	}
+
+	return &ObjectInfo{
+		Key:          info.Key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+		ETag:         info.ETag,
+		Owner:        owner(info.Owner),
+		Dir:          false,
+		Filename:     Filename(info.UserMetadata),
+		Metadata:     info.UserMetadata,
+		Tags:         nil,
+	}, nil
+}
+
+// Filename returns the original file name from object metadata,
+// or an empty string if it was not recorded.
+func Filename(metadata map[string]string) string {
+	name, err := url.PathUnescape(metadata[metaFilename])
+	if err != nil {
+		return ""
+	}
+
+	return name
+}
+
+// HeaderFilename returns the original file name from the headers of a
+// response to a presigned or public link.
+func HeaderFilename(header http.Header) string {
+	return Filename(map[string]string{metaFilename: header.Get("X-Amz-Meta-" + metaFilename)})
+}
+
+// Download writes the object to path. An interrupted download is
+// continued from the partial file on the next call.
+func (c *Client) Download(ctx context.Context, key string, path string) error {
+	if !c.setup {
+		return errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+
+	if key == "" || path == "" {
+		return errors.New("key and path cannot be empty")
+	}
+
+	err := c.minioClient.FGetObject(ctx, c.bucketName, key, path, minio.GetObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Open returns a reader for the object, e.g. to write it to stdout.
+func (c *Client) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	if key == "" {
+		return nil, errors.New("key cannot be empty")
+	}
+
+	obj, err := c.minioClient.GetObject(ctx, c.bucketName, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+
+	return obj, nil
+}
@@ -0,0 +1,66 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// ObjectDetails is everything the server returns about a single object.
+type ObjectDetails struct {
+	ObjectInfo
+
+	VersionID    string
+	StorageClass string
+	Headers      http.Header
+	// Tags is nil if the provider does not support object tagging.
+	Tags map[string]string
+}
+
+func (c *Client) Inspect(ctx context.Context, key string) (*ObjectDetails, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	if key == "" {
+		return nil, errors.New("key cannot be empty")
+	}
+
+	info, err := c.minioClient.StatObject(ctx, c.bucketName, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", key, err)
+	}
+
+	d := &ObjectDetails{
+		ObjectInfo: ObjectInfo{
+			Key:          info.Key,
+			Size:         info.Size,
+			ContentType:  info.ContentType,
+			LastModified: info.LastModified,
+			ETag:         info.ETag,
+			Owner:        owner(info.Owner),
+			Dir:          false,
+			Filename:     Filename(info.UserMetadata),
+			Metadata:     info.UserMetadata,
+			Tags:         nil,
+		},
+		VersionID:    info.VersionID,
+		StorageClass: info.StorageClass,
+		Headers:      info.Metadata,
+		Tags:         nil,
+	}
+
+	d.Tags, err = c.Tags(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
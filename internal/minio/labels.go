@@ -0,0 +1,124 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/tags"
+)
+
+// SetLabels sets the tags and user metadata for all following uploads.
+func (c *Client) SetLabels(objectTags map[string]string, metadata map[string]string) error {
+	if !c.setup {
+		return errors.New("client is not set up")
+	}
+
+	if len(objectTags) > 0 {
+		err := c.provider.Require(FeatureTagging)
+		if err != nil {
+			return fmt.Errorf("failed to set tags: %w", err)
+		}
+
+		// minio-go drops invalid tags silently, so they are checked here.
+		_, err = tags.NewTags(objectTags, true)
+		if err != nil {
+			return fmt.Errorf("invalid tags: %w", err)
+		}
+	}
+
+	for k, v := range metadata {
+		err := ValidateMetadata(k, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.tags = objectTags
+	c.metadata = metadata
+
+	return nil
+}
+
+//nolint:gochecknoglobals // Compiled once, used for every metadata entry.
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// ValidateMetadata checks that a user metadata entry can be sent as a
+// X-Amz-Meta-* header.
+func ValidateMetadata(key string, value string) error {
+	if !metadataKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid metadata key %q, only letters, digits and dashes are allowed", key)
+	}
+
+	for _, r := range value {
+		if r < ' ' || r > '~' {
+			return fmt.Errorf("invalid metadata value for %s, only printable ASCII is allowed", key)
+		}
+	}
+
+	return nil
+}
+
+//nolint:gochecknoglobals // Compiled once, used for every tag value.
+var invalidTagChars = regexp.MustCompile(`[^a-zA-Z0-9+\-._:/@ =]`)
+
+const maxTagValueLength = 256
+
+// TagValue replaces characters that are not allowed in tag values, e.g.
+// to use a file name as tag.
+func TagValue(s string) string {
+	s = invalidTagChars.ReplaceAllString(s, "_")
+	if len(s) > maxTagValueLength {
+		s = s[:maxTagValueLength]
+	}
+
+	return s
+}
+
+// userMetadata combines the labels of the client, the metadata of an
+// upload and the original file name.
+func (c *Client) userMetadata(metadata map[string]string, name string) map[string]string {
+	m := make(map[string]string, len(c.metadata)+len(metadata))
+	maps.Copy(m, c.metadata)
+	maps.Copy(m, metadata)
+
+	return withFilename(m, name)
+}
+
+// Tags returns the tags of an object, or nil if the provider does not
+// support object tagging.
+func (c *Client) Tags(ctx context.Context, key string) (map[string]string, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	if !c.provider.Supports(FeatureTagging) {
+		return nil, nil //nolint:nilnil // Objects of such providers have no tags.
+	}
+
+	t, err := c.minioClient.GetObjectTagging(ctx, c.bucketName, key, minio.GetObjectTaggingOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags of %s: %w", key, err)
+	}
+
+	return t.ToMap(), nil
+}
+
+// MatchTags reports whether all wanted tags are set to the wanted values.
+func MatchTags(have map[string]string, want map[string]string) bool {
+	for k, v := range want {
+		got, ok := have[k]
+		if !ok || got != v {
+			return false
+		}
+	}
+
+	return true
+}
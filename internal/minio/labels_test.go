@@ -0,0 +1,65 @@
+package minio_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/devusSs/minly/internal/minio"
+)
+
+func TestTagValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "allowed characters", in: "report-2025_v1.2+final:a/b@c =d", want: "report-2025_v1.2+final:a/b@c =d"},
+		{name: "umlauts", in: "Überblick.pdf", want: "_berblick.pdf"},
+		{name: "brackets and quotes", in: `a (1) "b".txt`, want: "a _1_ _b_.txt"},
+		{name: "empty", in: "", want: ""},
+		{name: "too long", in: strings.Repeat("a", 300), want: strings.Repeat("a", 256)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := minio.TagValue(tt.in); got != tt.want {
+				t.Errorf("TagValue(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchTags(t *testing.T) {
+	t.Parallel()
+
+	have := map[string]string{"team": "infra", "env": "prod"}
+
+	tests := []struct {
+		name string
+		have map[string]string
+		want map[string]string
+		ok   bool
+	}{
+		{name: "no wanted tags", have: have, want: nil, ok: true},
+		{name: "one matching tag", have: have, want: map[string]string{"team": "infra"}, ok: true},
+		{name: "all matching tags", have: have, want: have, ok: true},
+		{name: "different value", have: have, want: map[string]string{"team": "web"}, ok: false},
+		{name: "missing tag", have: have, want: map[string]string{"owner": "me"}, ok: false},
+		{name: "empty value is not missing", have: map[string]string{"a": ""}, want: map[string]string{"a": ""}, ok: true},
+		{name: "object without tags", have: nil, want: map[string]string{"team": "infra"}, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := minio.MatchTags(tt.have, tt.want); got != tt.ok {
+				t.Errorf("MatchTags(%v, %v) = %v, want %v", tt.have, tt.want, got, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+	ETag         string
+	Owner        string
+	// Dir is set for common prefixes of non-recursive listings.
+	Dir bool
+	// Filename is the original file name, it is only set by Stat and
+	// metadata listings.
+	Filename string
+	Metadata map[string]string
+	// Tags is only set by tag listings.
+	Tags map[string]string
+}
+
+type ListOptions struct {
+	Prefix string
+	// Recursive lists objects in sub directories instead of returning
+	// them as directories.
+	Recursive bool
+	// Metadata fills in content type and user metadata. MinIO returns
+	// them with the listing, other providers need a request per object.
+	Metadata bool
+	// Tags fills in the object tags, MinIO returns them with metadata
+	// listings, otherwise they need a request per object.
+	Tags bool
+}
+
+// List returns all objects below prefix, including those in sub directories.
+func (c *Client) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	return c.ListObjects(ctx, ListOptions{Prefix: prefix, Recursive: true, Metadata: false, Tags: false})
+}
+
+func (c *Client) ListObjects(ctx context.Context, opts ListOptions) ([]ObjectInfo, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	var objects []ObjectInfo
+	for obj := range c.minioClient.ListObjects(ctx, c.bucketName, minio.ListObjectsOptions{
+		Prefix:       opts.Prefix,
+		Recursive:    opts.Recursive,
+		WithMetadata: opts.Metadata || opts.Tags,
+	}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", obj.Err)
+		}
+
+		info := ObjectInfo{
+			Key:          obj.Key,
+			Size:         obj.Size,
+			ContentType:  obj.ContentType,
+			LastModified: obj.LastModified,
+			ETag:         obj.ETag,
+			Owner:        owner(obj.Owner),
+			Dir:          !opts.Recursive && strings.HasSuffix(obj.Key, "/") && obj.LastModified.IsZero(),
+			Filename:     "",
+			Metadata:     nil,
+			Tags:         nil,
+		}
+
+		if opts.Metadata && !info.Dir {
+			err := c.fillMetadata(ctx, &info, obj.UserMetadata)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if opts.Tags && !info.Dir {
+			info.Tags = obj.UserTags
+			if len(info.Tags) == 0 {
+				var err error
+				info.Tags, err = c.Tags(ctx, info.Key)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		objects = append(objects, info)
+	}
+
+	return objects, nil
+}
+
+// fillMetadata uses the metadata of a listing if there is any and stats
+// the object otherwise.
+func (c *Client) fillMetadata(ctx context.Context, info *ObjectInfo, listed map[string]string) error {
+	if len(listed) > 0 {
+		info.Metadata = make(map[string]string, len(listed))
+		for k, v := range listed {
+			name, ok := cutPrefixFold(k, "X-Amz-Meta-")
+			if ok {
+				info.Metadata[name] = v
+				continue
+			}
+
+			if strings.EqualFold(k, "content-type") && info.ContentType == "" {
+				info.ContentType = v
+			}
+		}
+
+		info.Filename = Filename(info.Metadata)
+
+		return nil
+	}
+
+	stat, err := c.Stat(ctx, info.Key)
+	if err != nil {
+		return err
+	}
+
+	info.ContentType = stat.ContentType
+	info.Filename = stat.Filename
+	info.Metadata = stat.Metadata
+
+	return nil
+}
+
+func owner(o minio.Owner) string {
+	if o.DisplayName != "" {
+		return o.DisplayName
+	}
+
+	return o.ID
+}
+
+func cutPrefixFold(s string, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+
+	return s[len(prefix):], true
+}
@@ -0,0 +1,99 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Link returns a fresh link to an existing object, a public URL in
+// public mode and a presigned URL otherwise.
+func (c *Client) Link(ctx context.Context, key string) (*Object, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	obj := &Object{
+		Bucket:      c.bucketName,
+		Key:         key,
+		ContentType: "",
+		Size:        0,
+		SHA256:      "",
+		VersionID:   "",
+		URL:         nil,
+		Expires:     time.Time{},
+	}
+
+	var err error
+	if c.public {
+		obj.URL, err = c.publicURL(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build public URL: %w", err)
+		}
+
+		return obj, nil
+	}
+
+	obj.URL, err = c.generatePresignedURL(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+
+	obj.Expires = time.Now().Add(c.linkExpiry)
+
+	return obj, nil
+}
+
+// PutData uploads data to exactly the given key, replacing the object if
+// it exists. Unlike the Upload methods no prefix or random name is applied.
+func (c *Client) PutData(ctx context.Context, key string, data []byte, contentType string) (*Object, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	if key == "" {
+		return nil, errors.New("key cannot be empty")
+	}
+
+	sum := newChecksum()
+	_, _ = sum.Write(data)
+
+	return c.putKey(ctx, key, contentType, sum, func(bucket string, key string) (minio.UploadInfo, error) {
+		//nolint:wrapcheck // Wrapped by putKey.
+		return c.minioClient.PutObject(ctx, bucket, key, bytes.NewReader(data), int64(len(data)),
+			minio.PutObjectOptions{ContentType: contentType, Checksum: c.checksumType()})
+	})
+}
+
+func (c *Client) RemoveObject(ctx context.Context, key string) error {
+	if !c.setup {
+		return errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+
+	if key == "" {
+		return errors.New("key cannot be empty")
+	}
+
+	err := c.minioClient.RemoveObject(ctx, c.bucketName, key, minio.RemoveObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to remove object %s: %w", key, err)
+	}
+
+	return nil
+}
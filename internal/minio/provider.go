@@ -0,0 +1,208 @@
+package minio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+)
+
+type Feature string
+
+// Features minly uses that not every provider implements, each one is
+// checked with Require or Supports before it is used.
+const (
+	FeatureBucketPolicy Feature = "bucket policies"
+	FeatureTagging      Feature = "object tagging"
+	FeatureVersioning   Feature = "bucket versioning"
+	FeaturePostPolicy   Feature = "POST policy uploads"
+	FeatureChecksum     Feature = "upload checksums"
+)
+
+var ErrUnsupported = errors.New("not supported by provider")
+
+// Provider describes how to talk to an S3 provider. Addressing, Signature
+// and the region from Region are used for every request, including
+// presigned URLs, which are therefore signed for the host and region the
+// provider expects.
+type Provider struct {
+	Name        string
+	Addressing  string
+	Signature   string
+	FixedRegion string
+	Unsupported []Feature
+}
+
+const (
+	ProviderMinIO  = "minio"
+	ProviderAWS    = "aws"
+	ProviderR2     = "r2"
+	ProviderB2     = "b2"
+	ProviderWasabi = "wasabi"
+	ProviderCustom = "custom"
+
+	AddressingAuto    = "auto"
+	AddressingPath    = "path"
+	AddressingVirtual = "virtual"
+
+	SignatureV4 = "v4"
+	SignatureV2 = "v2"
+)
+
+func Providers() []string {
+	return []string{ProviderMinIO, ProviderAWS, ProviderR2, ProviderB2, ProviderWasabi, ProviderCustom}
+}
+
+func NewProvider(name string, addressing string, signature string) (Provider, error) {
+	// Non-empty addressing and signature values override the preset.
+	p, err := providerPreset(name)
+	if err != nil {
+		return Provider{}, err
+	}
+
+	if addressing != "" {
+		p.Addressing = addressing
+	}
+
+	if signature != "" {
+		p.Signature = signature
+	}
+
+	switch p.Addressing {
+	case AddressingAuto, AddressingPath, AddressingVirtual:
+	default:
+		return Provider{}, fmt.Errorf("unknown addressing style %s", p.Addressing)
+	}
+
+	switch p.Signature {
+	case SignatureV4, SignatureV2:
+	default:
+		return Provider{}, fmt.Errorf("unknown signature version %s", p.Signature)
+	}
+
+	return p, nil
+}
+
+func (p Provider) Supports(f Feature) bool {
+	for _, u := range p.Unsupported {
+		if u == f {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p Provider) Require(f Feature) error {
+	if !p.Supports(f) {
+		return fmt.Errorf("%w: %s does not support %s", ErrUnsupported, p.Name, f)
+	}
+
+	return nil
+}
+
+func (p Provider) Region(endpoint string, configured string) string {
+	// Some providers use a fixed region, others encode it in the endpoint.
+	if p.FixedRegion != "" {
+		return p.FixedRegion
+	}
+
+	switch p.Name {
+	case ProviderAWS, ProviderB2, ProviderWasabi:
+		if region := regionFromEndpoint(endpoint); region != "" {
+			return region
+		}
+	}
+
+	return configured
+}
+
+func (p Provider) bucketLookup() minio.BucketLookupType {
+	switch p.Addressing {
+	case AddressingPath:
+		return minio.BucketLookupPath
+	case AddressingVirtual:
+		return minio.BucketLookupDNS
+	default:
+		return minio.BucketLookupAuto
+	}
+}
+
+func providerPreset(name string) (Provider, error) {
+	switch name {
+	case ProviderMinIO, "":
+		return Provider{
+			Name:        ProviderMinIO,
+			Addressing:  AddressingPath,
+			Signature:   SignatureV4,
+			FixedRegion: "",
+			Unsupported: nil,
+		}, nil
+	case ProviderAWS:
+		return Provider{
+			Name:        ProviderAWS,
+			Addressing:  AddressingVirtual,
+			Signature:   SignatureV4,
+			FixedRegion: "",
+			Unsupported: nil,
+		}, nil
+	case ProviderR2:
+		return Provider{
+			Name:        ProviderR2,
+			Addressing:  AddressingPath,
+			Signature:   SignatureV4,
+			FixedRegion: "auto",
+			Unsupported: []Feature{
+				FeatureBucketPolicy, FeatureTagging, FeatureVersioning, FeaturePostPolicy, FeatureChecksum,
+			},
+		}, nil
+	case ProviderB2:
+		return Provider{
+			Name:        ProviderB2,
+			Addressing:  AddressingVirtual,
+			Signature:   SignatureV4,
+			FixedRegion: "",
+			Unsupported: []Feature{
+				FeatureBucketPolicy, FeatureTagging, FeaturePostPolicy, FeatureChecksum,
+			},
+		}, nil
+	case ProviderWasabi:
+		return Provider{
+			Name:        ProviderWasabi,
+			Addressing:  AddressingVirtual,
+			Signature:   SignatureV4,
+			FixedRegion: "",
+			Unsupported: []Feature{FeatureChecksum},
+		}, nil
+	case ProviderCustom:
+		return Provider{
+			Name:        ProviderCustom,
+			Addressing:  AddressingAuto,
+			Signature:   SignatureV4,
+			FixedRegion: "",
+			Unsupported: nil,
+		}, nil
+	default:
+		return Provider{}, fmt.Errorf(
+			"unknown provider %s, must be one of %s",
+			name,
+			strings.Join(Providers(), ", "),
+		)
+	}
+}
+
+const endpointRegionParts = 3
+
+func regionFromEndpoint(endpoint string) string {
+	host, _, _ := strings.Cut(endpoint, ":")
+
+	// Matches s3.<region>.amazonaws.com, s3.<region>.backblazeb2.com
+	// and s3.<region>.wasabisys.com.
+	parts := strings.SplitN(host, ".", endpointRegionParts)
+	if len(parts) != endpointRegionParts || parts[0] != "s3" || !strings.Contains(parts[2], ".") {
+		return ""
+	}
+
+	return parts[1]
+}
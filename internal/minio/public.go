@@ -0,0 +1,204 @@
+package minio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// SetPublic enables public links below prefix. Providers with bucket
+// policies get an anonymous-read statement for the prefix, others need a
+// base URL which already serves the bucket publicly, e.g. r2.dev or a custom
+// domain for R2.
+func (c *Client) SetPublic(prefix string, baseURL *url.URL) error {
+	if !c.setup {
+		return errors.New("client is not set up")
+	}
+
+	err := c.provider.Require(FeatureBucketPolicy)
+	if err != nil && baseURL == nil {
+		return fmt.Errorf("public links need an anonymous-read bucket policy or a public base URL: %w", err)
+	}
+
+	if prefix == "" || strings.HasPrefix(prefix, "/") {
+		return errors.New("public prefix cannot be empty or start with a slash")
+	}
+
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	c.public = true
+	c.publicPolicy = err == nil
+	c.publicPrefix = prefix
+	c.publicBaseURL = baseURL
+
+	return nil
+}
+
+func (c *Client) Public() bool {
+	return c.public
+}
+
+// PublicPolicy reports whether public links rely on a bucket policy managed
+// by minly, it is false if the public base URL has to serve them instead.
+func (c *Client) PublicPolicy() bool {
+	return c.publicPolicy
+}
+
+const publicPolicySid = "MinlyPublicRead"
+
+type bucketPolicy struct {
+	Version   string            `json:"Version"`
+	Statement []json.RawMessage `json:"Statement"`
+}
+
+// policyStatement uses any for the fields which can be a single value or a
+// list, e.g. "Principal": "*" and "Principal": {"AWS": ["*"]} are the same.
+type policyStatement struct {
+	Sid       string `json:"Sid"`
+	Effect    string `json:"Effect"`
+	Principal any    `json:"Principal"`
+	Action    any    `json:"Action"`
+	Resource  any    `json:"Resource"`
+}
+
+// grants reports whether s grants exactly what want does.
+func (s policyStatement) grants(want policyStatement) bool {
+	return s.Effect == want.Effect &&
+		slices.Equal(principals(s.Principal), principals(want.Principal)) &&
+		slices.Equal(policyValues(s.Action), policyValues(want.Action)) &&
+		slices.Equal(policyValues(s.Resource), policyValues(want.Resource))
+}
+
+func principals(p any) []string {
+	if m, ok := p.(map[string]any); ok {
+		return policyValues(m["AWS"])
+	}
+
+	return policyValues(p)
+}
+
+// policyValues returns a policy value which is a string or a list of
+// strings as a sorted list.
+func policyValues(v any) []string {
+	var values []string
+
+	switch v := v.(type) {
+	case string:
+		values = []string{v}
+	case []string:
+		values = slices.Clone(v)
+	case []any:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	slices.Sort(values)
+
+	return values
+}
+
+func (c *Client) ensurePublicPolicy(ctx context.Context) error {
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+
+	current, err := c.minioClient.GetBucketPolicy(ctx, c.bucketName)
+	if err != nil {
+		return fmt.Errorf("failed to get bucket policy: %w", err)
+	}
+
+	policy := bucketPolicy{Version: "2012-10-17", Statement: nil}
+	if current != "" {
+		err = json.Unmarshal([]byte(current), &policy)
+		if err != nil {
+			return fmt.Errorf("failed to decode bucket policy: %w", err)
+		}
+	}
+
+	resource := "arn:aws:s3:::" + c.bucketName + "/" + c.publicPrefix + "*"
+	want := policyStatement{
+		Sid:       publicPolicySid,
+		Effect:    "Allow",
+		Principal: map[string]any{"AWS": []string{"*"}},
+		Action:    []string{"s3:GetObject"},
+		Resource:  []string{resource},
+	}
+
+	// Statements added by someone else are kept as they are,
+	// only our own statement is added or replaced.
+	statements := make([]json.RawMessage, 0, len(policy.Statement)+1)
+	for _, raw := range policy.Statement {
+		var s policyStatement
+		err = json.Unmarshal(raw, &s)
+		if err == nil && s.Sid == publicPolicySid {
+			// Earlier public prefixes stay readable, their links are
+			// recorded as permanent.
+			resources := policyValues(s.Resource)
+			if !slices.Contains(resources, resource) {
+				resources = append(resources, resource)
+				slices.Sort(resources)
+			}
+
+			want.Resource = resources
+
+			if s.grants(want) {
+				return nil
+			}
+
+			continue
+		}
+
+		statements = append(statements, raw)
+	}
+
+	var b []byte
+	b, err = json.Marshal(want)
+	if err != nil {
+		return fmt.Errorf("failed to encode policy statement: %w", err)
+	}
+
+	policy.Statement = append(statements, b)
+
+	b, err = json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to encode bucket policy: %w", err)
+	}
+
+	err = c.minioClient.SetBucketPolicy(ctx, c.bucketName, string(b))
+	if err != nil {
+		return fmt.Errorf("failed to set bucket policy: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) publicURL(objectName string) (*url.URL, error) {
+	if objectName == "" {
+		return nil, errors.New("object name cannot be empty")
+	}
+
+	// A configured base URL (e.g. a CDN) is used as is, the object
+	// name is appended to its path.
+	if c.publicBaseURL != nil {
+		return c.publicBaseURL.JoinPath(objectName), nil
+	}
+
+	endpoint := c.minioClient.EndpointURL()
+
+	u := &url.URL{Scheme: endpoint.Scheme, Host: endpoint.Host}
+	if c.provider.Addressing == AddressingVirtual {
+		u.Host = c.bucketName + "." + endpoint.Host
+		return u.JoinPath(objectName), nil
+	}
+
+	return u.JoinPath(c.bucketName, objectName), nil
+}
@@ -0,0 +1,134 @@
+package minio_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestPublicPolicy(t *testing.T) {
+	t.Parallel()
+
+	const (
+		oldPrefix = "arn:aws:s3:::" + testBucket + "/old/*"
+		newPrefix = "arn:aws:s3:::" + testBucket + "/public/*"
+		foreign   = `{"Sid":"Other","Effect":"Deny","Principal":"*","Action":"s3:DeleteObject",` +
+			`"Resource":"arn:aws:s3:::minly/*"}`
+	)
+
+	minlyStatement := func(resource string) string {
+		return `{"Sid":"MinlyPublicRead","Effect":"Allow","Principal":{"AWS":["*"]},` +
+			`"Action":["s3:GetObject"],"Resource":` + resource + `}`
+	}
+
+	tests := []struct {
+		name   string
+		policy string
+		// wantResources are the resources of the minly statement.
+		wantResources []string
+		wantForeign   bool
+		wantWrite     bool
+	}{
+		{
+			name:          "no policy",
+			policy:        "",
+			wantResources: []string{newPrefix},
+			wantForeign:   false,
+			wantWrite:     true,
+		},
+		{
+			name:          "statement for the prefix exists",
+			policy:        `{"Version":"2012-10-17","Statement":[` + minlyStatement(`"`+newPrefix+`"`) + `]}`,
+			wantResources: []string{newPrefix},
+			wantForeign:   false,
+			wantWrite:     false,
+		},
+		{
+			name:          "earlier prefix stays readable",
+			policy:        `{"Version":"2012-10-17","Statement":[` + minlyStatement(`"`+oldPrefix+`"`) + `]}`,
+			wantResources: []string{oldPrefix, newPrefix},
+			wantForeign:   false,
+			wantWrite:     true,
+		},
+		{
+			name: "foreign statements are kept",
+			policy: `{"Version":"2012-10-17","Statement":[` + foreign + `,` +
+				minlyStatement(`["`+oldPrefix+`"]`) + `]}`,
+			wantResources: []string{oldPrefix, newPrefix},
+			wantForeign:   true,
+			wantWrite:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := newFakeS3(nil, tt.policy)
+			c := newTestClient(t, s)
+
+			err := c.SetPublic("public", nil)
+			if err != nil {
+				t.Fatalf("SetPublic() error = %v", err)
+			}
+
+			_, err = c.PutData(context.Background(), "public/page.html", []byte("page"), "text/html")
+			if err != nil {
+				t.Fatalf("PutData() error = %v", err)
+			}
+
+			if wrote := len(s.requestsWith("PUT /"+testBucket+"/?policy")) > 0; wrote != tt.wantWrite {
+				t.Errorf("policy written = %v, want %v", wrote, tt.wantWrite)
+			}
+
+			var policy struct {
+				Statement []struct {
+					Sid      string `json:"Sid"`
+					Resource any    `json:"Resource"`
+				} `json:"Statement"`
+			}
+
+			err = json.Unmarshal([]byte(s.currentPolicy()), &policy)
+			if err != nil {
+				t.Fatalf("invalid policy %s: %v", s.currentPolicy(), err)
+			}
+
+			var resources []string
+			var hasForeign bool
+			for _, st := range policy.Statement {
+				switch st.Sid {
+				case "MinlyPublicRead":
+					resources = values(st.Resource)
+				case "Other":
+					hasForeign = true
+				}
+			}
+
+			if !slices.Equal(resources, tt.wantResources) {
+				t.Errorf("resources = %v, want %v", resources, tt.wantResources)
+			}
+
+			if hasForeign != tt.wantForeign {
+				t.Errorf("foreign statement kept = %v, want %v", hasForeign, tt.wantForeign)
+			}
+		})
+	}
+}
+
+// values returns a policy value which is a string or a list of strings.
+func values(v any) []string {
+	if s, ok := v.(string); ok {
+		return []string{s}
+	}
+
+	var result []string
+	if list, ok := v.([]any); ok {
+		for _, e := range list {
+			s, _ := e.(string)
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,143 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Receive is a presigned request others can use to upload into the bucket.
+type Receive struct {
+	Method string
+	URL    *url.URL
+	// Key is the object key for PUT and the key prefix for POST uploads.
+	Key string
+	// Fields are the form fields of a POST upload, they have to be sent
+	// before the file.
+	Fields  map[string]string
+	Expires time.Time
+}
+
+type ReceiveOptions struct {
+	// Prefix is the only place uploads are allowed, it has to end with a slash.
+	Prefix  string
+	MaxSize int64
+	// ContentType is an exact content type or a type like image/* that
+	// matches all subtypes. Empty allows any type.
+	ContentType string
+	Expiry      time.Duration
+}
+
+// ReceiveFilename is replaced with the name of the uploaded file in POST keys.
+const ReceiveFilename = "${filename}"
+
+func (c *Client) PresignPut(ctx context.Context, key string, expiry time.Duration) (*Receive, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	if key == "" || strings.HasSuffix(key, "/") {
+		return nil, errors.New("key cannot be empty or end with a slash")
+	}
+
+	err := c.createBucketIfNotExists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bucket if not exists: %w", err)
+	}
+
+	var u *url.URL
+	u, err = c.minioClient.PresignedPutObject(ctx, c.bucketName, key, expiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign PUT request: %w", err)
+	}
+
+	return &Receive{
+		Method:  "PUT",
+		URL:     u,
+		Key:     key,
+		Fields:  nil,
+		Expires: time.Now().Add(expiry),
+	}, nil
+}
+
+func (c *Client) PresignPost(ctx context.Context, opts ReceiveOptions) (*Receive, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	err := c.provider.Require(FeaturePostPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("use a presigned PUT URL instead: %w", err)
+	}
+
+	if opts.Prefix == "" || !strings.HasSuffix(opts.Prefix, "/") {
+		return nil, errors.New("prefix cannot be empty and has to end with a slash")
+	}
+
+	if opts.MaxSize <= 0 {
+		return nil, errors.New("max size has to be positive")
+	}
+
+	err = c.createBucketIfNotExists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bucket if not exists: %w", err)
+	}
+
+	expires := time.Now().Add(opts.Expiry)
+
+	policy := minio.NewPostPolicy()
+	err = errors.Join(
+		policy.SetBucket(c.bucketName),
+		policy.SetKeyStartsWith(opts.Prefix),
+		policy.SetExpires(expires.UTC()),
+		policy.SetContentLengthRange(1, opts.MaxSize),
+		policy.SetSuccessStatusAction("201"),
+		setContentTypeCondition(policy, opts.ContentType),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build POST policy: %w", err)
+	}
+
+	var u *url.URL
+	var fields map[string]string
+	u, fields, err = c.minioClient.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign POST policy: %w", err)
+	}
+
+	// The policy only requires the prefix, uploading with the original
+	// file name keeps several uploads apart.
+	fields["key"] = opts.Prefix + ReceiveFilename
+
+	return &Receive{
+		Method:  "POST",
+		URL:     u,
+		Key:     opts.Prefix,
+		Fields:  fields,
+		Expires: expires,
+	}, nil
+}
+
+func setContentTypeCondition(policy *minio.PostPolicy, contentType string) error {
+	switch {
+	case contentType == "":
+		return policy.SetContentTypeStartsWith("") //nolint:wrapcheck // Joined by caller.
+	case strings.HasSuffix(contentType, "/*"):
+		return policy.SetContentTypeStartsWith(strings.TrimSuffix(contentType, "*")) //nolint:wrapcheck // Joined by caller.
+	default:
+		return policy.SetContentType(contentType) //nolint:wrapcheck // Joined by caller.
+	}
+}
@@ -1,18 +1,58 @@
 package minio
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net/url"
+	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 )
 
-func (c *Client) UploadFile(ctx context.Context, path string) (*url.URL, error) {
+type Object struct {
+	Bucket      string
+	Key         string
+	ContentType string
+	Size        int64
+	// SHA256 is the hex checksum of the uploaded data.
+	SHA256 string
+	// VersionID is only set for replaced and restored objects.
+	VersionID string
+	URL       *url.URL
+	// Expires is zero for public links.
+	Expires time.Time
+}
+
+func (c *Client) SetPrefix(prefix string) error {
+	if !c.setup {
+		return errors.New("client is not set up")
+	}
+
+	if strings.HasPrefix(prefix, "/") {
+		return fmt.Errorf("object prefix cannot start with a slash, got %s", prefix)
+	}
+
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	c.prefix = prefix
+
+	return nil
+}
+
+// UploadFile uploads the file at path. The name is the original file name
+// stored with the object, it defaults to the name of the file at path.
+func (c *Client) UploadFile(ctx context.Context, path string, name string) (*Object, error) {
 	if !c.setup {
 		return nil, errors.New("client is not set up")
 	}
@@ -36,31 +76,220 @@ func (c *Client) UploadFile(ctx context.Context, path string) (*url.URL, error)
 		return nil, fmt.Errorf("failed to get content type: %w", err)
 	}
 
-	err = c.createBucketIfNotExists(ctx)
+	if name == "" {
+		name = filepath.Base(path)
+	}
+
+	sum := newChecksum()
+
+	return c.put(ctx, objectName, contentType, sum, func(bucket string, key string) (minio.UploadInfo, error) {
+		return c.putFile(ctx, bucket, key, path, sum, minio.PutObjectOptions{
+			ContentType:  contentType,
+			UserMetadata: c.userMetadata(nil, name),
+			UserTags:     c.tags,
+			Checksum:     c.checksumType(),
+		})
+	})
+}
+
+// putFile uploads the file at path and hashes it while it is read, so sum
+// covers exactly the bytes that were sent even if the file changes.
+func (c *Client) putFile(
+	ctx context.Context,
+	bucket string,
+	key string,
+	path string,
+	sum *checksum,
+	opts minio.PutObjectOptions,
+) (minio.UploadInfo, error) {
+	var info minio.UploadInfo
+
+	f, err := os.Open(path)
+	if err != nil {
+		return info, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var stat os.FileInfo
+	stat, err = f.Stat()
+	if err != nil {
+		return info, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	info, err = c.minioClient.PutObject(ctx, bucket, key, io.TeeReader(f, sum), stat.Size(), opts)
+
+	//nolint:wrapcheck // Wrapped by putKey.
+	return info, err
+}
+
+func (c *Client) UploadStream(
+	ctx context.Context,
+	r io.Reader,
+	name string,
+	contentType string,
+) (*Object, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	if r == nil {
+		return nil, errors.New("reader cannot be nil")
+	}
+
+	if name == "" || strings.Contains(name, "/") {
+		return nil, errors.New("name cannot be empty or contain slashes")
+	}
+
+	uid, err := uuid.NewRandom()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate random UUID: %w", err)
+	}
+
+	// The random directory keeps the readable name unguessable and unique.
+	objectName := uid.String() + "/" + name
+
+	// The stream can only be read once, it is hashed while uploading.
+	sum := newChecksum()
+
+	return c.put(ctx, objectName, contentType, sum, func(bucket string, key string) (minio.UploadInfo, error) {
+		//nolint:wrapcheck // Wrapped by put.
+		return c.minioClient.PutObject(ctx, bucket, key, io.TeeReader(r, sum), -1, minio.PutObjectOptions{
+			ContentType:  contentType,
+			UserMetadata: c.userMetadata(nil, name),
+			UserTags:     c.tags,
+			PartSize:     streamPartSize,
+			Checksum:     c.checksumType(),
+		})
+	})
+}
+
+// UploadData uploads data that is not stored in a file, e.g. pasted text.
+// The metadata is stored as user metadata of the object.
+func (c *Client) UploadData(
+	ctx context.Context,
+	data []byte,
+	name string,
+	contentType string,
+	metadata map[string]string,
+) (*Object, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	if len(data) == 0 {
+		return nil, errors.New("data cannot be empty")
+	}
+
+	objectName, err := randomizeObjectName(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to randomize object name: %w", err)
+	}
+
+	sum := newChecksum()
+	_, _ = sum.Write(data)
+
+	return c.put(ctx, objectName, contentType, sum, func(bucket string, key string) (minio.UploadInfo, error) {
+		//nolint:wrapcheck // Wrapped by put.
+		return c.minioClient.PutObject(ctx, bucket, key, bytes.NewReader(data), int64(len(data)),
+			minio.PutObjectOptions{
+				ContentType:  contentType,
+				UserMetadata: c.userMetadata(metadata, name),
+				UserTags:     c.tags,
+				Checksum:     c.checksumType(),
+			})
+	})
+}
+
+// Streams of unknown size are uploaded in parts of this size, which allows
+// objects of up to 160 GiB while keeping the memory usage low.
+const streamPartSize = 16 * 1024 * 1024
+
+type uploadFunc func(bucket string, key string) (minio.UploadInfo, error)
+
+func (c *Client) put(
+	ctx context.Context,
+	objectName string,
+	contentType string,
+	sum *checksum,
+	upload uploadFunc,
+) (*Object, error) {
+	return c.putKey(ctx, c.ObjectKey(objectName), contentType, sum, upload)
+}
+
+// ObjectKey returns the key an object name is uploaded to, including
+// the object prefix and, in public mode, the public prefix.
+func (c *Client) ObjectKey(objectName string) string {
+	objectName = c.prefix + objectName
+	if c.public {
+		objectName = c.publicPrefix + objectName
+	}
+
+	return objectName
+}
+
+// putKey uploads to key and verifies the upload against sum, a mismatching
+// object is removed again.
+func (c *Client) putKey(
+	ctx context.Context,
+	key string,
+	contentType string,
+	sum *checksum,
+	upload uploadFunc,
+) (*Object, error) {
+	err := c.createBucketIfNotExists(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bucket if not exists: %w", err)
 	}
 
-	_, err = c.minioClient.FPutObject(
-		ctx,
-		c.bucketName,
-		objectName,
-		path,
-		minio.PutObjectOptions{
-			ContentType: contentType,
-		},
-	)
+	if c.publicPolicy {
+		err = c.ensurePublicPolicy(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to ensure public bucket policy: %w", err)
+		}
+	}
+
+	var info minio.UploadInfo
+	info, err = upload(c.bucketName, key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload file: %w", err)
 	}
 
-	var presignedURL *url.URL
-	presignedURL, err = c.generatePresignedURL(ctx, objectName)
+	err = c.verify(ctx, key, info, sum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify upload: %w", err)
+	}
+
+	var obj *Object
+	obj, err = c.Link(ctx, key)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate presigned URL: %w", err)
+		return nil, err
 	}
 
-	return presignedURL, nil
+	obj.ContentType = contentType
+	obj.Size = info.Size
+	obj.SHA256 = sum.SHA256()
+
+	return obj, nil
+}
+
+// Object names are random, the original file name is kept as metadata so
+// downloads can restore it. Metadata has to be ASCII, so it is escaped.
+const metaFilename = "Filename"
+
+func withFilename(metadata map[string]string, name string) map[string]string {
+	m := make(map[string]string, len(metadata)+1)
+	maps.Copy(m, metadata)
+	m[metaFilename] = url.PathEscape(name)
+
+	return m
 }
 
 func randomizeObjectName(file string) (string, error) {
@@ -0,0 +1,249 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+var ErrVersioningDisabled = errors.New("bucket versioning is not enabled")
+
+type ObjectVersion struct {
+	VersionID    string
+	Size         int64
+	LastModified time.Time
+	ETag         string
+	Latest       bool
+	DeleteMarker bool
+}
+
+// Versioning reports whether versioning is enabled for the bucket.
+func (c *Client) Versioning(ctx context.Context) (bool, error) {
+	if !c.setup {
+		return false, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return false, errors.New("context cannot be nil")
+	}
+
+	if !c.provider.Supports(FeatureVersioning) {
+		return false, nil
+	}
+
+	cfg, err := c.minioClient.GetBucketVersioning(ctx, c.bucketName)
+	if err != nil {
+		return false, fmt.Errorf("failed to get bucket versioning: %w", err)
+	}
+
+	return cfg.Enabled(), nil
+}
+
+// SetVersioning enables or suspends versioning for the bucket. Existing
+// versions are kept when versioning is suspended.
+func (c *Client) SetVersioning(ctx context.Context, enabled bool) error {
+	if !c.setup {
+		return errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+
+	err := c.provider.Require(FeatureVersioning)
+	if err != nil {
+		return fmt.Errorf("failed to set bucket versioning: %w", err)
+	}
+
+	err = c.createBucketIfNotExists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create bucket if not exists: %w", err)
+	}
+
+	if enabled {
+		err = c.minioClient.EnableVersioning(ctx, c.bucketName)
+	} else {
+		err = c.minioClient.SuspendVersioning(ctx, c.bucketName)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to set bucket versioning: %w", err)
+	}
+
+	return nil
+}
+
+// Versions returns all versions of the object at key, newest first.
+func (c *Client) Versions(ctx context.Context, key string) ([]ObjectVersion, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	if key == "" {
+		return nil, errors.New("key cannot be empty")
+	}
+
+	var versions []ObjectVersion
+	for obj := range c.minioClient.ListObjects(ctx, c.bucketName, minio.ListObjectsOptions{
+		Prefix:       key,
+		Recursive:    true,
+		WithVersions: true,
+	}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("failed to list versions: %w", obj.Err)
+		}
+
+		// The prefix also matches longer keys.
+		if obj.Key != key {
+			continue
+		}
+
+		versions = append(versions, ObjectVersion{
+			VersionID:    obj.VersionID,
+			Size:         obj.Size,
+			LastModified: obj.LastModified,
+			ETag:         obj.ETag,
+			Latest:       obj.IsLatest,
+			DeleteMarker: obj.IsDeleteMarker,
+		})
+	}
+
+	return versions, nil
+}
+
+// ReplaceFile uploads the file at path as a new version of the object at
+// key. The bucket needs versioning so earlier versions are kept.
+func (c *Client) ReplaceFile(ctx context.Context, key string, path string, name string) (*Object, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	if key == "" || path == "" || name == "" {
+		return nil, errors.New("key, path and name cannot be empty")
+	}
+
+	err := c.requireVersioning(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var contentType string
+	contentType, err = getContentType(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get content type: %w", err)
+	}
+
+	sum := newChecksum()
+
+	var versionID string
+
+	obj, err := c.putKey(ctx, key, contentType, sum, func(bucket string, key string) (minio.UploadInfo, error) {
+		info, putErr := c.putFile(ctx, bucket, key, path, sum, minio.PutObjectOptions{
+			ContentType:  contentType,
+			UserMetadata: c.userMetadata(nil, name),
+			UserTags:     c.tags,
+			Checksum:     c.checksumType(),
+		})
+		versionID = info.VersionID
+
+		return info, putErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.linkVersion(ctx, obj, versionID)
+}
+
+// RestoreVersion makes an earlier version the latest one by copying it,
+// the versions in between are kept.
+func (c *Client) RestoreVersion(ctx context.Context, key string, versionID string) (*Object, error) {
+	if !c.setup {
+		return nil, errors.New("client is not set up")
+	}
+
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	if key == "" || versionID == "" {
+		return nil, errors.New("key and version cannot be empty")
+	}
+
+	err := c.requireVersioning(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var info minio.UploadInfo
+	info, err = c.minioClient.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: c.bucketName, Object: key},
+		minio.CopySrcOptions{Bucket: c.bucketName, Object: key, VersionID: versionID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore version %s of %s: %w", versionID, key, err)
+	}
+
+	var obj *Object
+	obj, err = c.Link(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	obj.Size = info.Size
+
+	return c.linkVersion(ctx, obj, info.VersionID)
+}
+
+func (c *Client) requireVersioning(ctx context.Context) error {
+	err := c.provider.Require(FeatureVersioning)
+	if err != nil {
+		return fmt.Errorf("failed to keep earlier versions: %w", err)
+	}
+
+	var enabled bool
+	enabled, err = c.Versioning(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !enabled {
+		return ErrVersioningDisabled
+	}
+
+	return nil
+}
+
+// linkVersion pins presigned links to the version, public links always
+// serve the latest version.
+func (c *Client) linkVersion(ctx context.Context, obj *Object, versionID string) (*Object, error) {
+	obj.VersionID = versionID
+
+	if c.public || versionID == "" {
+		return obj, nil
+	}
+
+	params := url.Values{}
+	params.Set("versionId", versionID)
+
+	u, err := c.minioClient.PresignedGetObject(ctx, c.bucketName, obj.Key, c.linkExpiry, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+
+	obj.URL = u
+
+	return obj, nil
+}
@@ -0,0 +1,40 @@
+package minio_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/devusSs/minly/internal/minio"
+)
+
+func TestReplaceFileRemovesOnlyTheCorruptVersion(t *testing.T) {
+	t.Parallel()
+
+	s := newFakeS3(http.Header{
+		"X-Amz-Version-Id":      {"v2"},
+		"X-Amz-Checksum-Crc32c": {"AAAAAA=="},
+	}, "")
+	c := newTestClient(t, s)
+
+	path := filepath.Join(t.TempDir(), "report.pdf")
+
+	err := os.WriteFile(path, []byte("new version"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.ReplaceFile(context.Background(), "files/report.pdf", path, "report.pdf")
+	if !errors.Is(err, minio.ErrChecksumMismatch) {
+		t.Fatalf("ReplaceFile() error = %v, want %v", err, minio.ErrChecksumMismatch)
+	}
+
+	want := []string{"DELETE /" + testBucket + "/files/report.pdf?versionId=v2"}
+	if got := s.requestsWith("DELETE "); !slices.Equal(got, want) {
+		t.Errorf("deletes = %v, want %v", got, want)
+	}
+}
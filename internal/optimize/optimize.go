@@ -0,0 +1,165 @@
+package optimize
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"github.com/devusSs/minly/internal/sanitize"
+)
+
+type Options struct {
+	// MaxDimension limits the longer side in pixels, 0 keeps the size.
+	MaxDimension int
+	// Quality is the JPEG quality from 1 to 100, PNGs are always lossless.
+	Quality int
+}
+
+type Result struct {
+	// Path is the optimized copy, or the original path if optimizing did not help.
+	Path         string
+	Optimized    bool
+	OriginalSize int64
+	Size         int64
+	Width        int
+	Height       int
+}
+
+func (r *Result) Saved() int64 {
+	return r.OriginalSize - r.Size
+}
+
+// Cleanup removes the optimized copy, it is a no-op for unchanged files.
+func (r *Result) Cleanup() error {
+	if !r.Optimized {
+		return nil
+	}
+
+	err := os.Remove(r.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove optimized copy: %w", err)
+	}
+
+	return nil
+}
+
+func Image(path string, opts Options) (*Result, error) {
+	if path == "" {
+		return nil, errors.New("file path cannot be empty")
+	}
+
+	if opts.Quality < 1 || opts.Quality > 100 {
+		return nil, fmt.Errorf("quality must be between 1 and 100, got %d", opts.Quality)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	unchanged := &Result{
+		Path:         path,
+		Optimized:    false,
+		OriginalSize: int64(len(data)),
+		Size:         int64(len(data)),
+		Width:        0,
+		Height:       0,
+	}
+
+	mtype := mimetype.Detect(data)
+
+	var isJPEG bool
+	switch {
+	case mtype.Is("image/jpeg"):
+		isJPEG = true
+	case mtype.Is("image/png"):
+	default:
+		return unchanged, nil
+	}
+
+	var img image.Image
+	img, _, err = image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	rgba := toRGBA(img)
+
+	// Re-encoding drops the EXIF block, so the rotation is applied to the pixels instead.
+	if isJPEG {
+		rgba = orient(rgba, sanitize.JPEGOrientation(data))
+	}
+
+	rgba = downscale(rgba, opts.MaxDimension)
+
+	var buf bytes.Buffer
+	if isJPEG {
+		err = jpeg.Encode(&buf, rgba, &jpeg.Options{Quality: opts.Quality})
+	} else {
+		enc := png.Encoder{CompressionLevel: png.BestCompression, BufferPool: nil}
+		err = enc.Encode(&buf, rgba)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	resized := rgba.Bounds().Dx() != img.Bounds().Dx() || rgba.Bounds().Dy() != img.Bounds().Dy()
+
+	// Only keep the result if it is actually smaller, a re-encoded
+	// and already optimized file can easily grow.
+	if !resized && int64(buf.Len()) >= unchanged.OriginalSize {
+		unchanged.Width = rgba.Bounds().Dx()
+		unchanged.Height = rgba.Bounds().Dy()
+
+		return unchanged, nil
+	}
+
+	var f *os.File
+	f, err = os.CreateTemp("", "minly-optimized-*"+filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create optimized copy: %w", err)
+	}
+
+	_, err = f.Write(buf.Bytes())
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to write optimized copy: %w", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to close optimized copy: %w", err)
+	}
+
+	return &Result{
+		Path:         f.Name(),
+		Optimized:    true,
+		OriginalSize: unchanged.OriginalSize,
+		Size:         int64(buf.Len()),
+		Width:        rgba.Bounds().Dx(),
+		Height:       rgba.Bounds().Dy(),
+	}, nil
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+
+	return rgba
+}
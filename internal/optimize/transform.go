@@ -0,0 +1,94 @@
+package optimize
+
+import (
+	"image"
+)
+
+// downscale shrinks the image with a box filter so the longer side
+// is at most maxDimension pixels. Images are never scaled up.
+func downscale(src *image.RGBA, maxDimension int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if maxDimension <= 0 || (sw <= maxDimension && sh <= maxDimension) {
+		return src
+	}
+
+	dw, dh := maxDimension, sh*maxDimension/sw
+	if sh > sw {
+		dw, dh = sw*maxDimension/sh, maxDimension
+	}
+
+	dw, dh = max(dw, 1), max(dh, 1)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for dy := range dh {
+		y0 := dy * sh / dh
+		y1 := max((dy+1)*sh/dh, y0+1)
+
+		for dx := range dw {
+			x0 := dx * sw / dw
+			x1 := max((dx+1)*sw/dw, x0+1)
+
+			var r, g, b, a, n int
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride:]
+				for x := x0; x < x1; x++ {
+					p := row[x*4 : x*4+4]
+					r += int(p[0])
+					g += int(p[1])
+					b += int(p[2])
+					a += int(p[3])
+					n++
+				}
+			}
+
+			d := dst.Pix[dy*dst.Stride+dx*4 : dy*dst.Stride+dx*4+4]
+			d[0], d[1], d[2], d[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n) //nolint:gosec // Averages of uint8 values.
+		}
+	}
+
+	return dst
+}
+
+// orient applies an EXIF orientation (1 to 8) to the pixels.
+func orient(src *image.RGBA, orientation uint16) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+
+	// Orientations 5 to 8 swap width and height.
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := range h {
+		for x := range w {
+			var nx, ny int
+
+			switch orientation {
+			case 2: // mirrored horizontally
+				nx, ny = w-1-x, y
+			case 3: // rotated 180
+				nx, ny = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				nx, ny = x, h-1-y
+			case 5: // transposed
+				nx, ny = y, x
+			case 6: // rotated 90 clockwise
+				nx, ny = h-1-y, x
+			case 7: // transversed
+				nx, ny = h-1-y, w-1-x
+			case 8: // rotated 90 counter-clockwise
+				nx, ny = y, w-1-x
+			}
+
+			copy(dst.Pix[ny*dst.Stride+nx*4:ny*dst.Stride+nx*4+4], src.Pix[y*src.Stride+x*4:y*src.Stride+x*4+4])
+		}
+	}
+
+	return dst
+}
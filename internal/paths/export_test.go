@@ -0,0 +1,6 @@
+package paths
+
+// CopyDir is the fallback of Migrate for directories on other filesystems.
+//
+//nolint:gochecknoglobals // Exported for tests only.
+var CopyDir = copyDir
@@ -0,0 +1,338 @@
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+func SetHome(dir string) {
+	home = dir
+}
+
+func Home() string {
+	// The --home flag takes precedence over the environment variable.
+	if home != "" {
+		return home
+	}
+
+	return os.Getenv(homeEnv)
+}
+
+func ConfigDir() (string, error) {
+	base, err := configBase()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config base directory: %w", err)
+	}
+
+	return setupDir(base)
+}
+
+func StateDir(elem ...string) (string, error) {
+	base, err := stateBase()
+	if err != nil {
+		return "", fmt.Errorf("failed to get state base directory: %w", err)
+	}
+
+	return setupDir(filepath.Join(append([]string{base}, elem...)...))
+}
+
+func CacheDir(elem ...string) (string, error) {
+	base, err := cacheBase()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache base directory: %w", err)
+	}
+
+	return setupDir(filepath.Join(append([]string{base}, elem...)...))
+}
+
+func LogsDir() (string, error) {
+	return StateDir("logs")
+}
+
+func Migrate() (bool, error) {
+	// Sandboxed runs using a home override never touch the legacy tree.
+	if Home() != "" {
+		return false, nil
+	}
+
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return false, fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	legacy := filepath.Join(userHome, legacyDirName)
+
+	_, err = os.Stat(legacy)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to stat legacy directory %s: %w", legacy, err)
+	}
+
+	var configBaseDir, stateBaseDir, cacheBaseDir string
+	configBaseDir, err = configBase()
+	if err != nil {
+		return false, fmt.Errorf("failed to get config base directory: %w", err)
+	}
+
+	stateBaseDir, err = stateBase()
+	if err != nil {
+		return false, fmt.Errorf("failed to get state base directory: %w", err)
+	}
+
+	cacheBaseDir, err = cacheBase()
+	if err != nil {
+		return false, fmt.Errorf("failed to get cache base directory: %w", err)
+	}
+
+	moves := []struct {
+		sub string
+		dst string
+	}{
+		{sub: "config", dst: configBaseDir},
+		{sub: "storage", dst: filepath.Join(stateBaseDir, "storage")},
+		{sub: "lastrun", dst: filepath.Join(stateBaseDir, "lastrun")},
+		{sub: "logs", dst: filepath.Join(stateBaseDir, "logs")},
+		{sub: "updates", dst: filepath.Join(cacheBaseDir, "updates")},
+	}
+
+	// Every subdirectory is migrated on its own, so a failed move is
+	// retried on the next run without abandoning the others.
+	var migrated bool
+	var errs []error
+	for _, m := range moves {
+		var moved bool
+		moved, err = moveDir(filepath.Join(legacy, m.sub), m.dst)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to migrate %s: %w", m.sub, err))
+			continue
+		}
+
+		migrated = migrated || moved
+	}
+
+	if len(errs) > 0 {
+		return migrated, errors.Join(errs...)
+	}
+
+	err = removeIfEmpty(legacy)
+	if err != nil {
+		return migrated, fmt.Errorf("failed to remove legacy directory %s: %w", legacy, err)
+	}
+
+	return migrated, nil
+}
+
+const (
+	homeEnv       = "MINLY_HOME"
+	appDirName    = "minly"
+	legacyDirName = ".minly"
+)
+
+var home string //nolint:gochecknoglobals // SetHome configures the package's subsequent directory lookups.
+
+func configBase() (string, error) {
+	if h := Home(); h != "" {
+		return filepath.Join(h, "config"), nil
+	}
+
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+func stateBase() (string, error) {
+	if h := Home(); h != "" {
+		return filepath.Join(h, "state"), nil
+	}
+
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+func cacheBase() (string, error) {
+	if h := Home(); h != "" {
+		return filepath.Join(h, "cache"), nil
+	}
+
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+func xdgDir(env string, fallback string) (string, error) {
+	// The XDG spec requires the variables to be absolute paths,
+	// relative values are considered invalid and must be ignored.
+	if dir := os.Getenv(env); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, appDirName), nil
+	}
+
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	return filepath.Join(userHome, fallback, appDirName), nil
+}
+
+func setupDir(dir string) (string, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	return dir, nil
+}
+
+// moveDir moves src to dst and reports whether it did. A missing src or a
+// dst that already has content is skipped, the latter keeps src in place.
+func moveDir(src string, dst string) (bool, error) {
+	_, err := os.Stat(src)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to stat %s: %w", src, err)
+	}
+
+	var empty bool
+	empty, err = isEmptyDir(dst)
+	if err != nil {
+		return false, err
+	}
+
+	if !empty {
+		return false, nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(dst), 0700)
+	if err != nil {
+		return false, fmt.Errorf("failed to create parent of %s: %w", dst, err)
+	}
+
+	// An empty destination may already exist because another package
+	// created it, os.Rename refuses to replace it on some platforms.
+	err = os.Remove(dst)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to remove empty destination %s: %w", dst, err)
+	}
+
+	err = os.Rename(src, dst)
+	if errors.Is(err, syscall.EXDEV) {
+		// XDG directories on another filesystem than the home directory
+		// cannot be renamed into, copy them instead.
+		err = copyDir(src, dst)
+		if err != nil {
+			return false, errors.Join(err, os.RemoveAll(dst))
+		}
+
+		err = os.RemoveAll(src)
+		if err != nil {
+			return true, fmt.Errorf("failed to remove %s after copying it: %w", src, err)
+		}
+
+		return true, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to move %s to %s: %w", src, dst, err)
+	}
+
+	return true, nil
+}
+
+// copyDir copies the tree at src to dst with file modes and symlinks.
+func copyDir(src string, dst string) error {
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path of %s: %w", p, err)
+		}
+
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to get file info of %s: %w", p, err)
+		}
+
+		switch {
+		case d.IsDir():
+			err = os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&fs.ModeSymlink != 0:
+			var link string
+			link, err = os.Readlink(p)
+			if err == nil {
+				err = os.Symlink(link, target)
+			}
+		case info.Mode().IsRegular():
+			err = copyFile(p, target, info.Mode().Perm())
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", p, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+
+	return nil
+}
+
+func copyFile(src string, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err //nolint:wrapcheck // Wrapped by copyDir.
+	}
+	defer in.Close()
+
+	var out *os.File
+	out, err = os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err //nolint:wrapcheck // Wrapped by copyDir.
+	}
+
+	_, err = io.Copy(out, in)
+
+	return errors.Join(err, out.Close())
+}
+
+// isEmptyDir reports whether dir is missing or an empty directory.
+func isEmptyDir(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return true, nil
+		}
+
+		return false, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	return len(entries) == 0, nil
+}
+
+// removeIfEmpty removes dir unless something is left in it, e.g. unknown
+// files or subdirectories whose destination already existed.
+func removeIfEmpty(dir string) error {
+	empty, err := isEmptyDir(dir)
+	if err != nil || !empty {
+		return err
+	}
+
+	err = os.Remove(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", dir, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package paths_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devusSs/minly/internal/paths"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name string
+		// legacy lists the subdirectories of ~/.minly, each gets a file.
+		legacy []string
+		// existing lists destinations that already have content.
+		existing []string
+		// empty lists destinations that exist but are empty.
+		empty        []string
+		wantMigrated bool
+		// wantMoved lists the destinations expected to hold the legacy file.
+		wantMoved []string
+		// wantKept lists the legacy subdirectories expected to be left over.
+		wantKept []string
+	}{
+		{
+			name:         "no legacy directory",
+			legacy:       nil,
+			existing:     nil,
+			empty:        nil,
+			wantMigrated: false,
+			wantMoved:    nil,
+			wantKept:     nil,
+		},
+		{
+			name:         "full legacy directory",
+			legacy:       []string{"config", "storage", "lastrun", "logs", "updates"},
+			existing:     nil,
+			empty:        nil,
+			wantMigrated: true,
+			wantMoved:    []string{"config", "state/storage", "state/lastrun", "state/logs", "cache/updates"},
+			wantKept:     nil,
+		},
+		{
+			name:         "empty destinations are replaced",
+			legacy:       []string{"config", "logs"},
+			existing:     nil,
+			empty:        []string{"config", "state/logs"},
+			wantMigrated: true,
+			wantMoved:    []string{"config", "state/logs"},
+			wantKept:     nil,
+		},
+		{
+			name:         "existing config does not block the rest",
+			legacy:       []string{"config", "storage", "logs"},
+			existing:     []string{"config"},
+			empty:        nil,
+			wantMigrated: true,
+			wantMoved:    []string{"state/storage", "state/logs"},
+			wantKept:     []string{"config"},
+		},
+		{
+			name:         "everything already migrated",
+			legacy:       []string{"storage"},
+			existing:     []string{"state/storage"},
+			empty:        nil,
+			wantMigrated: false,
+			wantMoved:    nil,
+			wantKept:     []string{"storage"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			userHome := filepath.Join(root, "home")
+			legacy := filepath.Join(userHome, ".minly")
+
+			t.Setenv("HOME", userHome)
+			t.Setenv("MINLY_HOME", "")
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+			t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+			t.Setenv("XDG_CACHE_HOME", filepath.Join(root, "cache"))
+			paths.SetHome("")
+
+			for _, sub := range tt.legacy {
+				writeFile(t, filepath.Join(legacy, sub, "legacy"))
+			}
+
+			for _, dst := range tt.existing {
+				writeFile(t, filepath.Join(xdgDir(root, dst), "existing"))
+			}
+
+			for _, dst := range tt.empty {
+				mkdir(t, xdgDir(root, dst))
+			}
+
+			migrated, err := paths.Migrate()
+			if err != nil {
+				t.Fatalf("Migrate() error = %v", err)
+			}
+
+			if migrated != tt.wantMigrated {
+				t.Errorf("Migrate() = %v, want %v", migrated, tt.wantMigrated)
+			}
+
+			for _, dst := range tt.wantMoved {
+				if !exists(filepath.Join(xdgDir(root, dst), "legacy")) {
+					t.Errorf("%s was not migrated", dst)
+				}
+			}
+
+			for _, dst := range tt.existing {
+				if exists(filepath.Join(xdgDir(root, dst), "legacy")) {
+					t.Errorf("%s was overwritten", dst)
+				}
+			}
+
+			for _, sub := range tt.wantKept {
+				if !exists(filepath.Join(legacy, sub, "legacy")) {
+					t.Errorf("legacy %s was not kept", sub)
+				}
+			}
+
+			if len(tt.wantKept) == 0 && exists(legacy) {
+				t.Errorf("legacy directory was not removed")
+			}
+
+			// A second run has nothing left to do.
+			migrated, err = paths.Migrate()
+			if err != nil {
+				t.Fatalf("second Migrate() error = %v", err)
+			}
+
+			if migrated {
+				t.Errorf("second Migrate() = true, want false")
+			}
+		})
+	}
+}
+
+func TestMigrateWithHome(t *testing.T) {
+	root := t.TempDir()
+	userHome := filepath.Join(root, "home")
+
+	t.Setenv("HOME", userHome)
+	t.Setenv("MINLY_HOME", filepath.Join(root, "minly"))
+	paths.SetHome("")
+
+	writeFile(t, filepath.Join(userHome, ".minly", "config", "legacy"))
+
+	migrated, err := paths.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	if migrated {
+		t.Errorf("Migrate() = true, want false with a home override")
+	}
+}
+
+// xdgDir maps a destination like "state/logs" to its directory below root,
+// "config" is the config base directory itself.
+func xdgDir(root string, dst string) string {
+	base, rest, _ := strings.Cut(dst, "/")
+
+	return filepath.Join(root, base, "minly", rest)
+}
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+
+	mkdir(t, filepath.Dir(path))
+
+	err := os.WriteFile(path, []byte("test"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mkdir(t *testing.T, dir string) {
+	t.Helper()
+
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+
+	return err == nil
+}
+
+func TestCopyDir(t *testing.T) {
+	t.Parallel()
+
+	src := filepath.Join(t.TempDir(), "src")
+	writeFile(t, filepath.Join(src, "config.json"))
+	writeFile(t, filepath.Join(src, "sub", "nested"))
+
+	err := os.Chmod(filepath.Join(src, "config.json"), 0640)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Symlink("config.json", filepath.Join(src, "link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "dst")
+
+	err = paths.CopyDir(src, dst)
+	if err != nil {
+		t.Fatalf("CopyDir() error = %v", err)
+	}
+
+	for _, name := range []string{"config.json", filepath.Join("sub", "nested")} {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil || string(data) != "test" {
+			t.Errorf("%s = %q, %v, want %q", name, data, err, "test")
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(dst, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("mode = %v, want %v", info.Mode().Perm(), os.FileMode(0640))
+	}
+
+	link, err := os.Readlink(filepath.Join(dst, "link"))
+	if err != nil || link != "config.json" {
+		t.Errorf("link = %q, %v, want %q", link, err, "config.json")
+	}
+
+	if !exists(filepath.Join(src, "config.json")) {
+		t.Error("CopyDir() removed the source")
+	}
+}
@@ -0,0 +1,245 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+type Policy struct {
+	// MaxSize is the maximum file size in bytes, 0 disables the check.
+	MaxSize      int64
+	AllowedMIME  []string
+	DeniedMIME   []string
+	DeniedNames  []string
+	ScanSecrets  bool
+	ScanMaxBytes int64
+}
+
+type Violation struct {
+	Rule   string
+	Detail string
+}
+
+func (v Violation) String() string {
+	return v.Rule + ": " + v.Detail
+}
+
+type Decision struct {
+	Path       string
+	Size       int64
+	MIME       string
+	Violations []Violation
+}
+
+func (d *Decision) Allowed() bool {
+	return len(d.Violations) == 0
+}
+
+func (p Policy) Check(file string) (*Decision, error) {
+	if file == "" {
+		return nil, errors.New("file path cannot be empty")
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", file)
+	}
+
+	var mtype *mimetype.MIME
+	mtype, err = mimetype.DetectFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect content type: %w", err)
+	}
+
+	d := p.decide(file, info.Size(), mtype)
+
+	// Secrets are only looked for in text files, binary formats
+	// like images or archives would mostly produce false positives.
+	if p.ScanSecrets && isText(mtype) {
+		var b []byte
+		b, err = p.read(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan file for secrets: %w", err)
+		}
+
+		d.Violations = append(d.Violations, scan(b)...)
+	}
+
+	return d, nil
+}
+
+// CheckData checks data that is not stored in a file, e.g. pasted text.
+// The name is only used for the file name rules.
+func (p Policy) CheckData(name string, data []byte) *Decision {
+	mtype := mimetype.Detect(data)
+	d := p.decide(name, int64(len(data)), mtype)
+
+	if p.ScanSecrets && isText(mtype) {
+		d.Violations = append(d.Violations, scan(data[:min(int64(len(data)), p.scanLimit())])...)
+	}
+
+	return d
+}
+
+func (p Policy) decide(name string, size int64, mtype *mimetype.MIME) *Decision {
+	d := &Decision{
+		Path:       name,
+		Size:       size,
+		MIME:       mtype.String(),
+		Violations: nil,
+	}
+
+	if p.MaxSize > 0 && size > p.MaxSize {
+		d.Violations = append(d.Violations, Violation{
+			Rule:   "max size",
+			Detail: fmt.Sprintf("file has %d bytes, the limit is %d bytes", size, p.MaxSize),
+		})
+	}
+
+	d.Violations = append(d.Violations, p.checkMIME(mtype)...)
+	d.Violations = append(d.Violations, p.checkName(filepath.Base(name))...)
+
+	return d
+}
+
+func (p Policy) checkMIME(mtype *mimetype.MIME) []Violation {
+	var violations []Violation
+
+	// mimetype reports parameters like "; charset=utf-8", patterns only
+	// match the bare media type.
+	media, _, _ := strings.Cut(mtype.String(), ";")
+
+	if len(p.AllowedMIME) > 0 && !matchAny(p.AllowedMIME, media) {
+		violations = append(violations, Violation{
+			Rule:   "allowed MIME types",
+			Detail: fmt.Sprintf("%s is not in %s", media, strings.Join(p.AllowedMIME, ", ")),
+		})
+	}
+
+	if pattern, ok := firstMatch(p.DeniedMIME, media); ok {
+		violations = append(violations, Violation{
+			Rule:   "denied MIME types",
+			Detail: fmt.Sprintf("%s matches %s", media, pattern),
+		})
+	}
+
+	return violations
+}
+
+func (p Policy) checkName(name string) []Violation {
+	pattern, ok := firstMatch(p.DeniedNames, strings.ToLower(name))
+	if !ok {
+		return nil
+	}
+
+	return []Violation{{
+		Rule:   "denied file names",
+		Detail: fmt.Sprintf("%s matches %s", name, pattern),
+	}}
+}
+
+func (p Policy) read(file string) ([]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	var b []byte
+	b, err = io.ReadAll(io.LimitReader(f, p.scanLimit()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return b, nil
+}
+
+func (p Policy) scanLimit() int64 {
+	if p.ScanMaxBytes <= 0 {
+		return defaultScanMaxBytes
+	}
+
+	return p.ScanMaxBytes
+}
+
+func scan(b []byte) []Violation {
+	var violations []Violation
+	for _, r := range secretRules() {
+		if r.match(b) {
+			violations = append(violations, Violation{
+				Rule:   "secret scan",
+				Detail: "file looks like it contains " + r.name,
+			})
+		}
+	}
+
+	return violations
+}
+
+const defaultScanMaxBytes = 10 * 1024 * 1024
+
+type secretRule struct {
+	name  string
+	match func([]byte) bool
+}
+
+func secretRules() []secretRule {
+	return []secretRule{
+		{name: "a private key", match: privateKeyPattern.Match},
+		{name: "an AWS access key id", match: awsAccessKeyPattern.Match},
+		{name: "an AWS secret access key", match: awsSecretKeyPattern.Match},
+		{name: "a GitHub token", match: githubTokenPattern.Match},
+		{name: "a Slack token", match: slackTokenPattern.Match},
+		{name: "secrets in .env syntax", match: envSecretPattern.Match},
+	}
+}
+
+//nolint:gochecknoglobals // Compiled once, the patterns never change.
+var (
+	privateKeyPattern   = regexp.MustCompile(`-----BEGIN ([A-Z]+ )*PRIVATE KEY( BLOCK)?-----`)
+	awsAccessKeyPattern = regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)
+	awsSecretKeyPattern = regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[:=]\s*["']?[A-Za-z0-9/+]{40}`)
+	githubTokenPattern  = regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)
+	slackTokenPattern   = regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)
+	envSecretPattern    = regexp.MustCompile(
+		`(?m)^\s*(export\s+)?[A-Z0-9_]*(SECRET|PASSWORD|PASSWD|TOKEN|API_KEY|ACCESS_KEY|PRIVATE_KEY)[A-Z0-9_]*\s*=\s*\S+`,
+	)
+)
+
+func isText(mtype *mimetype.MIME) bool {
+	for m := mtype; m != nil; m = m.Parent() {
+		if m.Is("text/plain") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchAny(patterns []string, value string) bool {
+	_, ok := firstMatch(patterns, value)
+	return ok
+}
+
+func firstMatch(patterns []string, value string) (string, bool) {
+	for _, pattern := range patterns {
+		ok, err := path.Match(strings.ToLower(pattern), value)
+		if err == nil && ok {
+			return pattern, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,38 @@
+package preview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Form is an upload form posting files straight to the bucket
+// using a presigned POST policy.
+type Form struct {
+	Title   string
+	Action  string
+	Fields  map[string]string
+	MaxSize int64
+	// Accept is the content type restriction of the policy, e.g. image/*.
+	Accept  string
+	Expires time.Time
+}
+
+func (f Form) Description() string {
+	return "Files up to " + FormatSize(f.MaxSize) + ", the form expires " + f.Expires.UTC().Format("2006-01-02 15:04 MST")
+}
+
+func RenderForm(f Form) ([]byte, error) {
+	if f.Action == "" {
+		return nil, errors.New("action cannot be empty")
+	}
+
+	var b bytes.Buffer
+	err := formTemplate.Execute(&b, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render upload form: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,62 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Index is the listing of a collection, rendered as HTML page and JSON manifest.
+type Index struct {
+	Name    string       `json:"name"`
+	Updated time.Time    `json:"updated"`
+	Files   []IndexEntry `json:"files"`
+	// ManifestURL is only shown on the HTML page.
+	ManifestURL string `json:"-"`
+}
+
+type IndexEntry struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	// Expires is zero for links that do not expire.
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+func (e IndexEntry) Kind() Kind {
+	return KindOf(e.ContentType)
+}
+
+func (e IndexEntry) Description() string {
+	return description(Page{Name: e.Name, URL: e.URL, ContentType: e.ContentType, Size: e.Size, Expires: e.Expires})
+}
+
+func RenderIndex(idx Index) ([]byte, error) {
+	if idx.Name == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+
+	var b bytes.Buffer
+	err := indexTemplate.Execute(&b, idx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render index page: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+func RenderManifest(idx Index) ([]byte, error) {
+	if idx.Name == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+
+	b, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render manifest: %w", err)
+	}
+
+	return b, nil
+}
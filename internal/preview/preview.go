@@ -0,0 +1,106 @@
+package preview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindImage Kind = "image"
+	KindVideo Kind = "video"
+	KindAudio Kind = "audio"
+	KindPDF   Kind = "pdf"
+	KindOther Kind = "other"
+)
+
+func KindOf(contentType string) Kind {
+	media, _, _ := strings.Cut(contentType, ";")
+
+	switch {
+	case strings.HasPrefix(media, "image/"):
+		return KindImage
+	case strings.HasPrefix(media, "video/"):
+		return KindVideo
+	case strings.HasPrefix(media, "audio/"):
+		return KindAudio
+	case media == "application/pdf":
+		return KindPDF
+	default:
+		return KindOther
+	}
+}
+
+type Page struct {
+	// Name is the original file name, URL the link to the object.
+	Name        string
+	URL         string
+	ContentType string
+	Size        int64
+	// Expires is zero for links that do not expire.
+	Expires time.Time
+}
+
+func Render(p Page) ([]byte, error) {
+	if p.Name == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+
+	if p.URL == "" {
+		return nil, errors.New("URL cannot be empty")
+	}
+
+	media, _, _ := strings.Cut(p.ContentType, ";")
+
+	data := pageData{
+		Page:        p,
+		Kind:        KindOf(p.ContentType),
+		MediaType:   strings.TrimSpace(media),
+		Description: description(p),
+	}
+
+	var b bytes.Buffer
+	err := pageTemplate.Execute(&b, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render preview page: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+type pageData struct {
+	Page
+	Kind        Kind
+	MediaType   string
+	Description string
+}
+
+func description(p Page) string {
+	d := FormatSize(p.Size)
+	if p.Expires.IsZero() {
+		return d + ", the link does not expire"
+	}
+
+	return d + ", the link expires " + p.Expires.UTC().Format("2006-01-02 15:04 MST")
+}
+
+// FormatSize formats a byte count with binary units, e.g. 1.5 MiB.
+func FormatSize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,162 @@
+package preview
+
+import "html/template"
+
+//nolint:gochecknoglobals // Parsed once, the template never changes.
+var pageTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex, nofollow">
+<title>{{.Name}}</title>
+<meta property="og:site_name" content="minly">
+<meta property="og:title" content="{{.Name}}">
+<meta property="og:description" content="{{.Description}}">
+{{- if eq .Kind "image"}}
+<meta property="og:type" content="website">
+<meta property="og:image" content="{{.URL}}">
+<meta property="og:image:type" content="{{.MediaType}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:image" content="{{.URL}}">
+{{- else if eq .Kind "video"}}
+<meta property="og:type" content="video.other">
+<meta property="og:video" content="{{.URL}}">
+<meta property="og:video:type" content="{{.MediaType}}">
+<meta name="twitter:card" content="summary">
+{{- else if eq .Kind "audio"}}
+<meta property="og:type" content="music.song">
+<meta property="og:audio" content="{{.URL}}">
+<meta property="og:audio:type" content="{{.MediaType}}">
+<meta name="twitter:card" content="summary">
+{{- else}}
+<meta property="og:type" content="website">
+<meta name="twitter:card" content="summary">
+{{- end}}
+<meta name="twitter:title" content="{{.Name}}">
+<meta name="twitter:description" content="{{.Description}}">
+<style>
+body { margin: 0; font-family: system-ui, sans-serif; background: #f7f7f7; color: #222; }
+main { max-width: 960px; margin: 0 auto; padding: 1.5rem; }
+h1 { font-size: 1.25rem; word-break: break-all; }
+img, video { display: block; max-width: 100%; max-height: 80vh; margin: 0 auto; }
+audio { width: 100%; }
+iframe { width: 100%; height: 80vh; border: 0; }
+p { color: #666; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Name}}</h1>
+{{- if eq .Kind "image"}}
+<img src="{{.URL}}" alt="{{.Name}}">
+{{- else if eq .Kind "video"}}
+<video src="{{.URL}}" controls preload="metadata"></video>
+{{- else if eq .Kind "audio"}}
+<audio src="{{.URL}}" controls preload="metadata"></audio>
+{{- else if eq .Kind "pdf"}}
+<iframe src="{{.URL}}" title="{{.Name}}"></iframe>
+{{- end}}
+<p>{{.Description}}</p>
+<p><a href="{{.URL}}" download="{{.Name}}">Download {{.Name}}</a></p>
+</main>
+</body>
+</html>
+`))
+
+//nolint:gochecknoglobals // Parsed once, the template never changes.
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex, nofollow">
+<title>{{.Name}}</title>
+<meta property="og:site_name" content="minly">
+<meta property="og:title" content="{{.Name}}">
+<meta property="og:description" content="{{len .Files}} files">
+<meta property="og:type" content="website">
+{{- range .Files}}{{if eq .Kind "image"}}
+<meta property="og:image" content="{{.URL}}">
+{{- break}}{{end}}{{end}}
+<meta name="twitter:card" content="summary">
+<meta name="twitter:title" content="{{.Name}}">
+<meta name="twitter:description" content="{{len .Files}} files">
+<style>
+body { margin: 0; font-family: system-ui, sans-serif; background: #f7f7f7; color: #222; }
+main { max-width: 960px; margin: 0 auto; padding: 1.5rem; }
+h1 { font-size: 1.25rem; word-break: break-all; }
+ul { list-style: none; padding: 0; }
+li { display: flex; align-items: center; gap: 1rem; padding: 0.5rem 0; border-bottom: 1px solid #ddd; }
+li img { width: 96px; height: 64px; object-fit: cover; }
+li span { color: #666; font-size: 0.875rem; }
+p { color: #666; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Name}}</h1>
+<ul>
+{{- range .Files}}
+<li>{{if eq .Kind "image"}}<img src="{{.URL}}" alt="{{.Name}}" loading="lazy">{{end}}
+<div><a href="{{.URL}}">{{.Name}}</a><br><span>{{.Description}}</span></div></li>
+{{- end}}
+</ul>
+<p>Updated {{.Updated.UTC.Format "2006-01-02 15:04 MST"}}{{if .ManifestURL}}, <a href="{{.ManifestURL}}">JSON manifest</a>{{end}}</p>
+</main>
+</body>
+</html>
+`))
+
+//nolint:gochecknoglobals // Parsed once, the template never changes.
+var formTemplate = template.Must(template.New("form").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex, nofollow">
+<title>{{.Title}}</title>
+<meta property="og:site_name" content="minly">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:type" content="website">
+<meta name="twitter:card" content="summary">
+<style>
+body { margin: 0; font-family: system-ui, sans-serif; background: #f7f7f7; color: #222; }
+main { max-width: 640px; margin: 0 auto; padding: 1.5rem; }
+h1 { font-size: 1.25rem; }
+p { color: #666; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+<form id="upload" action="{{.Action}}" method="post" enctype="multipart/form-data">
+{{- range $name, $value := .Fields}}
+<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{- end}}
+<input type="file" name="file" required{{if .Accept}} accept="{{.Accept}}"{{end}}>
+<button type="submit">Upload</button>
+</form>
+<p>{{.Description}}</p>
+<p id="error"></p>
+</main>
+<script>
+document.getElementById("upload").addEventListener("submit", function (e) {
+  var file = this.elements["file"].files[0];
+  if (file.size > {{.MaxSize}}) {
+    e.preventDefault();
+    document.getElementById("error").textContent = "The file is too large.";
+    return;
+  }
+  // The policy may only fix the start of the content type, the
+  // browser knows the actual type of the file.
+  var type = this.elements["Content-Type"];
+  if (type && (type.value === "" || type.value.endsWith("/"))) {
+    type.value = file.type || "application/octet-stream";
+  }
+});
+</script>
+</body>
+</html>
+`))
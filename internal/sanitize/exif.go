@@ -0,0 +1,68 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const orientationTag = 0x0112
+
+// orientation reads the Orientation tag from the first IFD of a raw
+// TIFF structure as found in EXIF blocks. It returns 0 if there is none.
+func orientation(tiff []byte) uint16 {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(tiff, []byte("II*\x00")):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(tiff, []byte("MM\x00*")):
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset+2 > len(tiff) {
+		return 0
+	}
+
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for i := range count {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+
+		if order.Uint16(tiff[entry:entry+2]) == orientationTag {
+			return order.Uint16(tiff[entry+8 : entry+10])
+		}
+	}
+
+	return 0
+}
+
+// orientationOnly builds a minimal big-endian TIFF structure containing
+// nothing but the Orientation tag, so viewers keep rotating the image correctly.
+func orientationOnly(value uint16) []byte {
+	b := make([]byte, 0, 26)
+	b = append(b, "MM\x00*"...)
+	b = binary.BigEndian.AppendUint32(b, 8)
+	b = binary.BigEndian.AppendUint16(b, 1)
+	b = binary.BigEndian.AppendUint16(b, orientationTag)
+	b = binary.BigEndian.AppendUint16(b, 3) // SHORT
+	b = binary.BigEndian.AppendUint32(b, 1)
+	b = binary.BigEndian.AppendUint16(b, value)
+	b = binary.BigEndian.AppendUint16(b, 0)
+	b = binary.BigEndian.AppendUint32(b, 0)
+
+	return b
+}
+
+// keepOrientation reports whether an orientation has to be preserved,
+// 1 is the default and needs no tag.
+func keepOrientation(value uint16) bool {
+	return value > 1 && value <= 8
+}
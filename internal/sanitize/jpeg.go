@@ -0,0 +1,162 @@
+package sanitize
+
+import (
+	"bytes"
+	"fmt"
+)
+
+const (
+	markerSOI   = 0xD8
+	markerEOI   = 0xD9
+	markerSOS   = 0xDA
+	markerAPP0  = 0xE0
+	markerAPP1  = 0xE1
+	markerAPP13 = 0xED
+	markerCOM   = 0xFE
+
+	jpegMarkerPrefix  = 0xFF
+	jpegSegmentHeader = 4
+)
+
+//nolint:gochecknoglobals // Constant segment identifiers.
+var (
+	jpegEXIFHeader   = []byte("Exif\x00\x00")
+	jpegXMPHeader    = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	jpegXMPExtHeader = []byte("http://ns.adobe.com/xmp/extension/\x00")
+	jpegIPTCHeader   = []byte("Photoshop 3.0\x00")
+)
+
+//nolint:gocognit,funlen // A single pass over the segments keeps the pixel data untouched.
+func stripJPEG(data []byte) ([]byte, []string, error) {
+	if len(data) < 4 || data[0] != jpegMarkerPrefix || data[1] != markerSOI {
+		return nil, nil, fmt.Errorf("%w: missing JPEG start marker", errMalformed)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+
+	var removed []string
+	var orient uint16
+
+	insertAt := len(out)
+	i := 2
+
+	for i < len(data) {
+		start := i
+		if data[i] != jpegMarkerPrefix {
+			return nil, nil, fmt.Errorf("%w: expected JPEG marker at offset %d", errMalformed, i)
+		}
+
+		// Markers may be preceded by any number of fill bytes.
+		for i < len(data) && data[i] == jpegMarkerPrefix {
+			i++
+		}
+
+		if i >= len(data) {
+			return nil, nil, fmt.Errorf("%w: truncated JPEG marker", errMalformed)
+		}
+
+		marker := data[i]
+		i++
+
+		// Everything from the start of scan on is entropy coded image data.
+		if marker == markerSOS || marker == markerEOI {
+			out = append(out, data[start:]...)
+			break
+		}
+
+		// Standalone markers without a length.
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			out = append(out, data[start:i]...)
+			continue
+		}
+
+		if i+2 > len(data) {
+			return nil, nil, fmt.Errorf("%w: truncated JPEG segment", errMalformed)
+		}
+
+		length := int(data[i])<<8 | int(data[i+1])
+		if length < 2 || i+length > len(data) {
+			return nil, nil, fmt.Errorf("%w: invalid JPEG segment length", errMalformed)
+		}
+
+		payload := data[i+2 : i+length]
+		i += length
+
+		switch {
+		case marker == markerAPP1 && bytes.HasPrefix(payload, jpegEXIFHeader):
+			orient = orientation(payload[len(jpegEXIFHeader):])
+			removed = appendUnique(removed, "EXIF")
+		case marker == markerAPP1 &&
+			(bytes.HasPrefix(payload, jpegXMPHeader) || bytes.HasPrefix(payload, jpegXMPExtHeader)):
+			removed = appendUnique(removed, "XMP")
+		case marker == markerAPP13 && bytes.HasPrefix(payload, jpegIPTCHeader):
+			removed = appendUnique(removed, "IPTC")
+		case marker == markerCOM:
+			removed = appendUnique(removed, "comment")
+		default:
+			out = append(out, data[start:i]...)
+
+			// A JFIF APP0 segment has to stay first, the orientation goes after it.
+			if marker == markerAPP0 && insertAt == 2 && start == 2 {
+				insertAt = len(out)
+			}
+		}
+	}
+
+	if keepOrientation(orient) {
+		payload := append(append([]byte{}, jpegEXIFHeader...), orientationOnly(orient)...)
+
+		segment := make([]byte, 0, jpegSegmentHeader+len(payload))
+		segment = append(segment, jpegMarkerPrefix, markerAPP1)
+		segment = append(segment, byte((len(payload)+2)>>8), byte(len(payload)+2))
+		segment = append(segment, payload...)
+
+		out = append(out[:insertAt], append(segment, out[insertAt:]...)...)
+	}
+
+	return out, removed, nil
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+
+	return append(list, value)
+}
+
+// JPEGOrientation returns the EXIF orientation of a JPEG image, 1 if it has none.
+func JPEGOrientation(data []byte) uint16 {
+	if len(data) < 4 || data[0] != jpegMarkerPrefix || data[1] != markerSOI {
+		return 1
+	}
+
+	i := 2
+	for i+4 <= len(data) && data[i] == jpegMarkerPrefix {
+		marker := data[i+1]
+		if marker == markerSOS || marker == markerEOI {
+			break
+		}
+
+		length := int(data[i+2])<<8 | int(data[i+3])
+		if length < 2 || i+2+length > len(data) {
+			break
+		}
+
+		payload := data[i+4 : i+2+length]
+		if marker == markerAPP1 && bytes.HasPrefix(payload, jpegEXIFHeader) {
+			if o := orientation(payload[len(jpegEXIFHeader):]); keepOrientation(o) {
+				return o
+			}
+
+			return 1
+		}
+
+		i += 2 + length
+	}
+
+	return 1
+}
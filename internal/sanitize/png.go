@@ -0,0 +1,86 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+//nolint:gochecknoglobals // Constant file signature.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+func stripPNG(data []byte) ([]byte, []string, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, nil, fmt.Errorf("%w: missing PNG signature", errMalformed)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+
+	var removed []string
+	var orient uint16
+
+	insertAt := -1
+	i := len(pngSignature)
+
+	for i < len(data) {
+		if i+8 > len(data) {
+			return nil, nil, fmt.Errorf("%w: truncated PNG chunk", errMalformed)
+		}
+
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		kind := string(data[i+4 : i+8])
+
+		end := i + 8 + length + 4
+		if length < 0 || end > len(data) {
+			return nil, nil, fmt.Errorf("%w: invalid PNG chunk length", errMalformed)
+		}
+
+		chunk := data[i:end]
+		payload := data[i+8 : i+8+length]
+		i = end
+
+		switch kind {
+		case "eXIf":
+			orient = orientation(payload)
+			removed = appendUnique(removed, "EXIF")
+		case "iTXt":
+			// XMP is stored as an iTXt chunk, other text chunks may carry
+			// IPTC profiles or free-form details like the author.
+			if bytes.HasPrefix(payload, []byte("XML:com.adobe.xmp\x00")) {
+				removed = appendUnique(removed, "XMP")
+			} else {
+				removed = appendUnique(removed, "text")
+			}
+		case "tEXt", "zTXt":
+			if bytes.Contains(payload[:min(len(payload), 32)], []byte("iptc")) {
+				removed = appendUnique(removed, "IPTC")
+			} else {
+				removed = appendUnique(removed, "text")
+			}
+		default:
+			out = append(out, chunk...)
+
+			if kind == "IHDR" {
+				insertAt = len(out)
+			}
+		}
+	}
+
+	if keepOrientation(orient) && insertAt > 0 {
+		chunk := pngChunk("eXIf", orientationOnly(orient))
+		out = append(out[:insertAt], append(chunk, out[insertAt:]...)...)
+	}
+
+	return out, removed, nil
+}
+
+func pngChunk(kind string, payload []byte) []byte {
+	chunk := make([]byte, 0, 12+len(payload))
+	chunk = binary.BigEndian.AppendUint32(chunk, uint32(len(payload))) //nolint:gosec // Payload is a few bytes.
+	chunk = append(chunk, kind...)
+	chunk = append(chunk, payload...)
+
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
@@ -0,0 +1,103 @@
+package sanitize
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+type Result struct {
+	// Path is the sanitized copy, or the original path if nothing was changed.
+	Path string
+	// Sanitized is true if the file is a supported image and was checked,
+	// even if it had no metadata to remove.
+	Sanitized bool
+	// Removed lists the kinds of metadata that were removed, e.g. EXIF.
+	Removed []string
+}
+
+// Changed reports whether metadata was removed and Path is a copy.
+func (r *Result) Changed() bool {
+	return len(r.Removed) > 0
+}
+
+// Cleanup removes the sanitized copy, it is a no-op for unchanged files.
+func (r *Result) Cleanup() error {
+	if !r.Changed() {
+		return nil
+	}
+
+	err := os.Remove(r.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove sanitized copy: %w", err)
+	}
+
+	return nil
+}
+
+func Image(path string) (*Result, error) {
+	if path == "" {
+		return nil, errors.New("file path cannot be empty")
+	}
+
+	mtype, err := mimetype.DetectFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect content type: %w", err)
+	}
+
+	var strip func([]byte) ([]byte, []string, error)
+	switch {
+	case mtype.Is("image/jpeg"):
+		strip = stripJPEG
+	case mtype.Is("image/png"):
+		strip = stripPNG
+	case mtype.Is("image/webp"):
+		strip = stripWebP
+	default:
+		return &Result{Path: path, Sanitized: false, Removed: nil}, nil
+	}
+
+	var data []byte
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	var out []byte
+	var removed []string
+	out, removed, err = strip(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to strip %s metadata: %w", mtype.String(), err)
+	}
+
+	if len(removed) == 0 {
+		return &Result{Path: path, Sanitized: true, Removed: nil}, nil
+	}
+
+	// Keep the extension, the object name is derived from it.
+	var f *os.File
+	f, err = os.CreateTemp("", "minly-sanitized-*"+filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sanitized copy: %w", err)
+	}
+
+	_, err = f.Write(out)
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to write sanitized copy: %w", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to close sanitized copy: %w", err)
+	}
+
+	return &Result{Path: f.Name(), Sanitized: true, Removed: removed}, nil
+}
+
+var errMalformed = errors.New("malformed image")
@@ -0,0 +1,370 @@
+package sanitize_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/devusSs/minly/internal/sanitize"
+)
+
+func TestImage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		file        string
+		data        []byte
+		wantChecked bool
+		wantRemoved []string
+		// wantKept and wantGone are byte sequences expected in and missing
+		// from the sanitized file.
+		wantKept [][]byte
+		wantGone [][]byte
+		// wantOrientation is checked for JPEG files only.
+		wantOrientation uint16
+	}{
+		{
+			name: "JPEG with all kinds of metadata",
+			file: "photo.jpg",
+			data: jpeg(
+				jpegSegment(0xE0, []byte("JFIF\x00\x01\x01")),
+				jpegSegment(0xE1, append([]byte("Exif\x00\x00"), tiff(6, "GPS 52.5N")...)),
+				jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>creator</x:xmpmeta>")),
+				jpegSegment(0xED, []byte("Photoshop 3.0\x00IPTC byline")),
+				jpegSegment(0xFE, []byte("shot on a phone")),
+				jpegSegment(0xDB, []byte("\x00quantization")),
+			),
+			wantChecked:     true,
+			wantRemoved:     []string{"EXIF", "XMP", "IPTC", "comment"},
+			wantKept:        [][]byte{[]byte("JFIF"), []byte("quantization"), jpegScan},
+			wantGone:        [][]byte{[]byte("GPS"), []byte("creator"), []byte("byline"), []byte("phone")},
+			wantOrientation: 6,
+		},
+		{
+			name: "JPEG without metadata",
+			file: "photo.jpg",
+			data: jpeg(
+				jpegSegment(0xE0, []byte("JFIF\x00\x01\x01")),
+				jpegSegment(0xDB, []byte("\x00quantization")),
+			),
+			wantChecked:     true,
+			wantRemoved:     nil,
+			wantKept:        [][]byte{[]byte("JFIF"), []byte("quantization"), jpegScan},
+			wantGone:        nil,
+			wantOrientation: 1,
+		},
+		{
+			name: "JPEG with default orientation",
+			file: "photo.jpg",
+			data: jpeg(
+				jpegSegment(0xE1, append([]byte("Exif\x00\x00"), tiff(1, "serial 1234")...)),
+			),
+			wantChecked:     true,
+			wantRemoved:     []string{"EXIF"},
+			wantKept:        [][]byte{jpegScan},
+			wantGone:        [][]byte{[]byte("Exif"), []byte("serial")},
+			wantOrientation: 1,
+		},
+		{
+			name: "PNG with all kinds of metadata",
+			file: "screenshot.png",
+			data: png(
+				pngChunk("IHDR", make([]byte, 13)),
+				pngChunk("eXIf", tiff(8, "GPS 52.5N")),
+				pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00<x:xmpmeta>creator</x:xmpmeta>")),
+				pngChunk("tEXt", []byte("Author\x00someone")),
+				pngChunk("IDAT", []byte("pixels")),
+				pngChunk("IEND", nil),
+			),
+			wantChecked:     true,
+			wantRemoved:     []string{"EXIF", "XMP", "text"},
+			wantKept:        [][]byte{[]byte("IHDR"), []byte("eXIf"), []byte("pixels"), []byte("IEND")},
+			wantGone:        [][]byte{[]byte("GPS"), []byte("creator"), []byte("someone")},
+			wantOrientation: 0,
+		},
+		{
+			name: "PNG without metadata",
+			file: "screenshot.png",
+			data: png(
+				pngChunk("IHDR", make([]byte, 13)),
+				pngChunk("IDAT", []byte("pixels")),
+				pngChunk("IEND", nil),
+			),
+			wantChecked:     true,
+			wantRemoved:     nil,
+			wantKept:        [][]byte{[]byte("pixels")},
+			wantGone:        nil,
+			wantOrientation: 0,
+		},
+		{
+			name: "WebP with EXIF and XMP",
+			file: "image.webp",
+			data: webp(
+				webpChunk("VP8X", []byte{0x0C, 0, 0, 0, 0, 0, 0, 0, 0, 0}),
+				webpChunk("VP8 ", []byte("pixels")),
+				webpChunk("EXIF", tiff(3, "GPS 52.5N")),
+				webpChunk("XMP ", []byte("<x:xmpmeta>creator</x:xmpmeta>")),
+			),
+			wantChecked:     true,
+			wantRemoved:     []string{"EXIF", "XMP"},
+			wantKept:        [][]byte{[]byte("VP8X"), []byte("pixels"), []byte("EXIF")},
+			wantGone:        [][]byte{[]byte("GPS"), []byte("creator")},
+			wantOrientation: 0,
+		},
+		{
+			name:            "text files are not images",
+			file:            "notes.txt",
+			data:            []byte("GPS 52.5N"),
+			wantChecked:     false,
+			wantRemoved:     nil,
+			wantKept:        [][]byte{[]byte("GPS")},
+			wantGone:        nil,
+			wantOrientation: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeFile(t, tt.file, tt.data)
+
+			r, err := sanitize.Image(path)
+			if err != nil {
+				t.Fatalf("Image() error = %v", err)
+			}
+
+			t.Cleanup(func() {
+				_ = r.Cleanup()
+			})
+
+			if r.Sanitized != tt.wantChecked {
+				t.Errorf("Sanitized = %v, want %v", r.Sanitized, tt.wantChecked)
+			}
+
+			if !slices.Equal(r.Removed, tt.wantRemoved) {
+				t.Errorf("Removed = %v, want %v", r.Removed, tt.wantRemoved)
+			}
+
+			if r.Changed() != (r.Path != path) {
+				t.Errorf("Changed() = %v, but Path is %s", r.Changed(), r.Path)
+			}
+
+			if filepath.Ext(r.Path) != filepath.Ext(path) {
+				t.Errorf("sanitized copy %s lost the extension", r.Path)
+			}
+
+			out, err := os.ReadFile(r.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, b := range tt.wantKept {
+				if !bytes.Contains(out, b) {
+					t.Errorf("sanitized file is missing %q", b)
+				}
+			}
+
+			for _, b := range tt.wantGone {
+				if bytes.Contains(out, b) {
+					t.Errorf("sanitized file still contains %q", b)
+				}
+			}
+
+			if tt.wantOrientation != 0 {
+				if o := sanitize.JPEGOrientation(out); o != tt.wantOrientation {
+					t.Errorf("JPEGOrientation() = %d, want %d", o, tt.wantOrientation)
+				}
+			}
+
+			assertValid(t, tt.file, out)
+		})
+	}
+}
+
+func TestImageCleanup(t *testing.T) {
+	t.Parallel()
+
+	path := writeFile(t, "photo.jpg", jpeg(jpegSegment(0xFE, []byte("comment"))))
+
+	r, err := sanitize.Image(path)
+	if err != nil {
+		t.Fatalf("Image() error = %v", err)
+	}
+
+	err = r.Cleanup()
+	if err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+
+	if _, err = os.Stat(r.Path); err == nil {
+		t.Error("sanitized copy was not removed")
+	}
+
+	if _, err = os.Stat(path); err != nil {
+		t.Errorf("original was removed: %v", err)
+	}
+}
+
+func TestImageMalformed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		file string
+		data []byte
+	}{
+		{
+			name: "truncated JPEG segment",
+			file: "photo.jpg",
+			data: []byte("\xFF\xD8\xFF\xE1\x10\x00Exif"),
+		},
+		{
+			name: "truncated PNG chunk",
+			file: "image.png",
+			data: append(png(pngChunk("IHDR", make([]byte, 13))), 0, 0, 1, 0, 'I', 'D', 'A', 'T'),
+		},
+		{
+			name: "WebP chunk longer than the file",
+			file: "image.webp",
+			data: webp(append([]byte("VP8 "), 0xFF, 0xFF, 0, 0)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := sanitize.Image(writeFile(t, tt.file, tt.data))
+			if err == nil {
+				t.Fatal("Image() error = nil for a malformed image")
+			}
+		})
+	}
+}
+
+// jpegScan is the start of scan segment and entropy coded data of every
+// test JPEG, it has to survive unchanged.
+//
+//nolint:gochecknoglobals // Shared test fixture.
+var jpegScan = []byte("\xFF\xDA\x00\x04scan-data\xFF\xD9")
+
+func jpeg(segments ...[]byte) []byte {
+	b := []byte{0xFF, 0xD8}
+	for _, s := range segments {
+		b = append(b, s...)
+	}
+
+	return append(b, jpegScan...)
+}
+
+func jpegSegment(marker byte, payload []byte) []byte {
+	b := []byte{0xFF, marker}
+	b = binary.BigEndian.AppendUint16(b, uint16(len(payload)+2))
+
+	return append(b, payload...)
+}
+
+// tiff builds a big-endian TIFF structure with an orientation tag and
+// extra data standing in for e.g. GPS tags.
+func tiff(orientation uint16, extra string) []byte {
+	b := []byte("MM\x00*")
+	b = binary.BigEndian.AppendUint32(b, 8)
+	b = binary.BigEndian.AppendUint16(b, 1)
+	b = binary.BigEndian.AppendUint16(b, 0x0112)
+	b = binary.BigEndian.AppendUint16(b, 3)
+	b = binary.BigEndian.AppendUint32(b, 1)
+	b = binary.BigEndian.AppendUint16(b, orientation)
+	b = binary.BigEndian.AppendUint16(b, 0)
+	b = binary.BigEndian.AppendUint32(b, 0)
+
+	return append(b, extra...)
+}
+
+func png(chunks ...[]byte) []byte {
+	b := []byte("\x89PNG\r\n\x1a\n")
+	for _, c := range chunks {
+		b = append(b, c...)
+	}
+
+	return b
+}
+
+func pngChunk(kind string, payload []byte) []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	b = append(b, kind...)
+	b = append(b, payload...)
+
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(b[4:]))
+}
+
+func webp(chunks ...[]byte) []byte {
+	var body []byte
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+
+	b := []byte("RIFF")
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(body)+4))
+	b = append(b, "WEBP"...)
+
+	return append(b, body...)
+}
+
+func webpChunk(kind string, payload []byte) []byte {
+	b := []byte(kind)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(payload)))
+	b = append(b, payload...)
+
+	if len(payload)%2 == 1 {
+		b = append(b, 0)
+	}
+
+	return b
+}
+
+// assertValid checks the container structure of a sanitized file.
+func assertValid(t *testing.T, file string, data []byte) {
+	t.Helper()
+
+	switch filepath.Ext(file) {
+	case ".png":
+		for i := 8; i < len(data); {
+			length := int(binary.BigEndian.Uint32(data[i:]))
+			end := i + 8 + length
+			if crc32.ChecksumIEEE(data[i+4:end]) != binary.BigEndian.Uint32(data[end:]) {
+				t.Errorf("PNG chunk %s has a wrong CRC", data[i+4:i+8])
+			}
+
+			i = end + 4
+		}
+	case ".webp":
+		if size := int(binary.LittleEndian.Uint32(data[4:8])); size != len(data)-8 {
+			t.Errorf("RIFF size = %d, want %d", size, len(data)-8)
+		}
+
+		// The flags have to match the metadata chunks that are left.
+		flags := data[20]
+		if flags&0x04 != 0 || flags&0x08 == 0 {
+			t.Errorf("VP8X flags = %#x, want only EXIF set", flags)
+		}
+	}
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+
+	err := os.WriteFile(path, data, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
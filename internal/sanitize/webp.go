@@ -0,0 +1,83 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	webpFlagXMP  = 0x04
+	webpFlagEXIF = 0x08
+)
+
+func stripWebP(data []byte) ([]byte, []string, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, nil, fmt.Errorf("%w: missing WebP header", errMalformed)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+
+	var removed []string
+	var orient uint16
+
+	flagsAt := -1
+	i := 12
+
+	for i < len(data) {
+		if i+8 > len(data) {
+			return nil, nil, fmt.Errorf("%w: truncated WebP chunk", errMalformed)
+		}
+
+		kind := string(data[i : i+4])
+		length := int(binary.LittleEndian.Uint32(data[i+4 : i+8]))
+
+		// Chunks are padded to an even size.
+		end := i + 8 + length + length%2
+		if length < 0 || end > len(data) {
+			return nil, nil, fmt.Errorf("%w: invalid WebP chunk length", errMalformed)
+		}
+
+		chunk := data[i:end]
+		payload := data[i+8 : i+8+length]
+		i = end
+
+		switch kind {
+		case "EXIF":
+			orient = orientation(bytes.TrimPrefix(payload, jpegEXIFHeader))
+			removed = appendUnique(removed, "EXIF")
+		case "XMP ":
+			removed = appendUnique(removed, "XMP")
+		default:
+			if kind == "VP8X" && length > 0 {
+				flagsAt = len(out) + 8
+			}
+
+			out = append(out, chunk...)
+		}
+	}
+
+	if len(removed) == 0 {
+		return data, nil, nil
+	}
+
+	if flagsAt > 0 {
+		out[flagsAt] &^= webpFlagXMP | webpFlagEXIF
+	}
+
+	// The extended format is required for metadata chunks,
+	// simple files without VP8X cannot carry the orientation.
+	if keepOrientation(orient) && flagsAt > 0 {
+		payload := orientationOnly(orient)
+
+		out = append(out, "EXIF"...)
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(payload))) //nolint:gosec // Payload is a few bytes.
+		out = append(out, payload...)
+		out[flagsAt] |= webpFlagEXIF
+	}
+
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8)) //nolint:gosec // Bounded by the input size.
+
+	return out, removed, nil
+}
@@ -0,0 +1,73 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Backend interface {
+	Name() string
+	Get(key Key) (string, error)
+	Set(key Key, value string) error
+	Delete(key Key) error
+	Writable() bool
+}
+
+type Options struct {
+	FilePath   string
+	Passphrase string
+	Command    string
+	SetCommand string
+	// MissingExitCode is the exit code of Command for a missing secret,
+	// 0 relies on the messages of well-known tools.
+	MissingExitCode int
+}
+
+const (
+	BackendKeyring = "keyring"
+	BackendFile    = "file"
+	BackendEnv     = "env"
+	BackendCommand = "command"
+)
+
+func Backends() []string {
+	return []string{BackendKeyring, BackendFile, BackendEnv, BackendCommand}
+}
+
+func NewBackend(name string, opts Options) (Backend, error) {
+	switch name {
+	case BackendKeyring:
+		return newKeyringBackend(), nil
+	case BackendFile:
+		return newFileBackend(opts.FilePath, opts.Passphrase)
+	case BackendEnv:
+		return newEnvBackend(), nil
+	case BackendCommand:
+		return newCommandBackend(opts.Command, opts.SetCommand, opts.MissingExitCode)
+	default:
+		return nil, fmt.Errorf("unknown secret backend %s", name)
+	}
+}
+
+func SetBackend(b Backend) {
+	backend = b
+}
+
+func Current() Backend {
+	// MINLY_SECRETS_ENV_ONLY always wins so the keyring is never touched.
+	if EnvOnly() {
+		return newEnvBackend()
+	}
+
+	if backend == nil {
+		return newKeyringBackend()
+	}
+
+	return backend
+}
+
+var ErrNotFound = errors.New("secret not found")
+
+var errReadOnly = errors.New("secret backend is read-only")
+
+var backend Backend //nolint:gochecknoglobals // SetBackend configures the package's subsequent secret operations.
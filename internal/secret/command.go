@@ -0,0 +1,147 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+type commandBackend struct {
+	getCommand      []string
+	setCommand      []string
+	missingExitCode int
+}
+
+func newCommandBackend(getCommand string, setCommand string, missingExitCode int) (*commandBackend, error) {
+	get := strings.Fields(getCommand)
+	if len(get) == 0 {
+		return nil, errors.New("secret command cannot be empty")
+	}
+
+	return &commandBackend{
+		getCommand:      get,
+		setCommand:      strings.Fields(setCommand),
+		missingExitCode: missingExitCode,
+	}, nil
+}
+
+func (b *commandBackend) Name() string {
+	return BackendCommand
+}
+
+func (b *commandBackend) Get(key Key) (string, error) {
+	out, err := runCommand(b.getCommand, key, nil)
+	if err != nil {
+		if b.missing(err) {
+			return "", fmt.Errorf("%w: %w", ErrNotFound, err)
+		}
+
+		return "", err
+	}
+
+	// Tools like pass print the secret on the first line and metadata after it.
+	value, _, _ := strings.Cut(string(out), "\n")
+	value = strings.TrimRight(value, "\r")
+	if value == "" {
+		return "", fmt.Errorf("%w: command returned an empty value for %s", ErrNotFound, key)
+	}
+
+	return value, nil
+}
+
+// missingMessages are printed by well-known password managers for entries
+// that do not exist, e.g. pass, macOS security, 1Password and Bitwarden.
+//
+//nolint:gochecknoglobals // Read-only list of messages.
+var missingMessages = []string{
+	"is not in the password store",
+	"could not be found",
+	"isn't an item",
+	"not found.",
+}
+
+// missing reports whether the get command failed because the secret does
+// not exist. Other failures, e.g. a locked agent, are real errors.
+func (b *commandBackend) missing(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+
+	if b.missingExitCode != 0 {
+		return exitErr.ExitCode() == b.missingExitCode
+	}
+
+	stderr := strings.ToLower(string(exitErr.Stderr))
+	for _, m := range missingMessages {
+		if strings.Contains(stderr, m) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *commandBackend) Set(key Key, value string) error {
+	if !b.Writable() {
+		return fmt.Errorf("%w: no secret set command configured", errReadOnly)
+	}
+
+	_, err := runCommand(b.setCommand, key, strings.NewReader(value+"\n"))
+
+	return err
+}
+
+func (b *commandBackend) Delete(_ Key) error {
+	return fmt.Errorf("%w: the command backend does not support deleting secrets", errReadOnly)
+}
+
+func (b *commandBackend) Writable() bool {
+	return len(b.setCommand) > 0
+}
+
+const commandTimeout = 30 * time.Second
+
+func runCommand(template []string, key Key, stdin *strings.Reader) ([]byte, error) {
+	args := make([]string, len(template))
+	for i, arg := range template {
+		args[i] = strings.ReplaceAll(arg, "{key}", string(key))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	//nolint:gosec // The command is configured by the user on purpose.
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+
+	err := cmd.Run()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitErr.Stderr = stderr.Bytes()
+
+			return nil, fmt.Errorf(
+				"%s failed: %w: %s",
+				args[0],
+				err,
+				strings.TrimSpace(stderr.String()),
+			)
+		}
+
+		return nil, fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,119 @@
+package secret_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devusSs/minly/internal/secret"
+)
+
+func TestCommandBackendGet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// script is the body of the shell script used as get command.
+		script          string
+		missingExitCode int
+		want            string
+		wantNotFound    bool
+		wantErr         bool
+	}{
+		{
+			name:            "value with metadata",
+			script:          "printf 'value\\nuser: me\\n'",
+			missingExitCode: 0,
+			want:            "value",
+			wantNotFound:    false,
+			wantErr:         false,
+		},
+		{
+			name:            "empty output",
+			script:          "exit 0",
+			missingExitCode: 0,
+			want:            "",
+			wantNotFound:    true,
+			wantErr:         true,
+		},
+		{
+			name:            "pass message",
+			script:          "echo \"Error: minly/$1 is not in the password store.\" >&2; exit 1",
+			missingExitCode: 0,
+			want:            "",
+			wantNotFound:    true,
+			wantErr:         true,
+		},
+		{
+			name:            "security message",
+			script:          "echo 'The specified item could not be found in the keychain.' >&2; exit 44",
+			missingExitCode: 0,
+			want:            "",
+			wantNotFound:    true,
+			wantErr:         true,
+		},
+		{
+			name:            "unknown failure",
+			script:          "echo 'gpg: decryption failed: No secret key' >&2; exit 2",
+			missingExitCode: 0,
+			want:            "",
+			wantNotFound:    false,
+			wantErr:         true,
+		},
+		{
+			name:            "configured exit code",
+			script:          "exit 1",
+			missingExitCode: 1,
+			want:            "",
+			wantNotFound:    true,
+			wantErr:         true,
+		},
+		{
+			name:            "other exit code",
+			script:          "echo 'agent is locked' >&2; exit 2",
+			missingExitCode: 1,
+			want:            "",
+			wantNotFound:    false,
+			wantErr:         true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			script := filepath.Join(t.TempDir(), "get.sh")
+
+			//nolint:gosec // The script has to be executable.
+			err := os.WriteFile(script, []byte("#!/bin/sh\n"+tt.script+"\n"), 0700)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := secret.NewBackend(secret.BackendCommand, secret.Options{
+				FilePath:        "",
+				Passphrase:      "",
+				Command:         script + " {key}",
+				SetCommand:      "",
+				MissingExitCode: tt.missingExitCode,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := b.Get(secret.MinioAccessKey)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if errors.Is(err, secret.ErrNotFound) != tt.wantNotFound {
+				t.Errorf("Get() error = %v, want not found %v", err, tt.wantNotFound)
+			}
+
+			if got != tt.want {
+				t.Errorf("Get() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package secret
+
+import (
+	"fmt"
+	"os"
+)
+
+type envBackend struct{}
+
+func newEnvBackend() *envBackend {
+	return &envBackend{}
+}
+
+func (b *envBackend) Name() string {
+	return BackendEnv
+}
+
+func (b *envBackend) Get(key Key) (string, error) {
+	value := os.Getenv(key.EnvName())
+	if value == "" {
+		return "", fmt.Errorf("%w: %s is not set", ErrNotFound, key.EnvName())
+	}
+
+	return value, nil
+}
+
+func (b *envBackend) Set(_ Key, _ string) error {
+	return fmt.Errorf("%w: secrets are read from environment variables", errReadOnly)
+}
+
+func (b *envBackend) Delete(_ Key) error {
+	return fmt.Errorf("%w: secrets are read from environment variables", errReadOnly)
+}
+
+func (b *envBackend) Writable() bool {
+	return false
+}
@@ -0,0 +1,266 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/devusSs/minly/internal/paths"
+)
+
+type fileBackend struct {
+	path       string
+	passphrase string
+
+	// values caches the decrypted secrets since deriving the key is slow on purpose.
+	values map[string]string
+}
+
+func newFileBackend(path string, passphrase string) (*fileBackend, error) {
+	if path == "" {
+		dir, err := paths.ConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get config directory: %w", err)
+		}
+
+		path = filepath.Join(dir, "secrets.enc")
+	}
+
+	if passphrase == "" {
+		passphrase = os.Getenv("MINLY_SECRET_PASSPHRASE")
+	}
+
+	return &fileBackend{path: path, passphrase: passphrase, values: nil}, nil
+}
+
+func (b *fileBackend) Name() string {
+	return BackendFile
+}
+
+func (b *fileBackend) Get(key Key) (string, error) {
+	values, err := b.load()
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := values[string(key)]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return value, nil
+}
+
+func (b *fileBackend) Set(key Key, value string) error {
+	values, err := b.load()
+	if err != nil {
+		return err
+	}
+
+	values[string(key)] = value
+
+	return b.save(values)
+}
+
+func (b *fileBackend) Delete(key Key) error {
+	values, err := b.load()
+	if err != nil {
+		return err
+	}
+
+	delete(values, string(key))
+
+	return b.save(values)
+}
+
+func (b *fileBackend) Writable() bool {
+	return true
+}
+
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+const (
+	encryptedFileVersion = 1
+	saltLength           = 32
+	keyLength            = 32
+	scryptN              = 1 << 15
+	scryptR              = 8
+	scryptP              = 1
+)
+
+func (b *fileBackend) load() (map[string]string, error) {
+	if b.values != nil {
+		return b.values, nil
+	}
+
+	raw, err := os.ReadFile(b.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return make(map[string]string), nil
+		}
+
+		return nil, fmt.Errorf("failed to read secret file %s: %w", b.path, err)
+	}
+
+	var ef encryptedFile
+	err = json.Unmarshal(raw, &ef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret file %s: %w", b.path, err)
+	}
+
+	if ef.Version != encryptedFileVersion {
+		return nil, fmt.Errorf("unsupported secret file version %d", ef.Version)
+	}
+
+	var aead cipher.AEAD
+	aead, err = b.cipher(ef.Salt, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var plain []byte
+	plain, err = aead.Open(nil, ef.Nonce, ef.Data, nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt secret file, wrong passphrase?")
+	}
+
+	values := make(map[string]string)
+	err = json.Unmarshal(plain, &values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode decrypted secrets: %w", err)
+	}
+
+	b.values = values
+
+	return values, nil
+}
+
+func (b *fileBackend) save(values map[string]string) error {
+	plain, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets: %w", err)
+	}
+
+	salt := make([]byte, saltLength)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	// A new file gets its passphrase here, it is confirmed since a typo
+	// would lock the user out of their secrets.
+	_, statErr := os.Stat(b.path)
+
+	var aead cipher.AEAD
+	aead, err = b.cipher(salt, errors.Is(statErr, os.ErrNotExist))
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	ef := encryptedFile{
+		Version: encryptedFileVersion,
+		Salt:    salt,
+		Nonce:   nonce,
+		Data:    aead.Seal(nil, nonce, plain, nil),
+	}
+
+	var raw []byte
+	raw, err = json.Marshal(ef)
+	if err != nil {
+		return fmt.Errorf("failed to encode secret file: %w", err)
+	}
+
+	tmpPath := b.path + ".tmp"
+
+	err = os.WriteFile(tmpPath, raw, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write secret file %s: %w", tmpPath, err)
+	}
+
+	err = os.Rename(tmpPath, b.path)
+	if err != nil {
+		return fmt.Errorf("failed to replace secret file %s: %w", b.path, err)
+	}
+
+	b.values = values
+
+	return nil
+}
+
+func (b *fileBackend) cipher(salt []byte, confirm bool) (cipher.AEAD, error) {
+	if b.passphrase == "" {
+		passphrase, err := readPassphrase(confirm)
+		if err != nil {
+			return nil, err
+		}
+
+		b.passphrase = passphrase
+	}
+
+	key, err := scrypt.Key([]byte(b.passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	var block cipher.Block
+	block, err = aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	var aead cipher.AEAD
+	aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	return aead, nil
+}
+
+func readPassphrase(confirm bool) (string, error) {
+	passphrase, err := GetInput("Enter the passphrase for the minly secret file")
+	if err != nil {
+		return "", fmt.Errorf(
+			"failed to get passphrase (set MINLY_SECRET_PASSPHRASE when not running in a terminal): %w",
+			err,
+		)
+	}
+
+	if passphrase == "" {
+		return "", errors.New("passphrase cannot be empty")
+	}
+
+	if !confirm {
+		return passphrase, nil
+	}
+
+	var repeated string
+	repeated, err = GetInput("Repeat the passphrase")
+	if err != nil {
+		return "", fmt.Errorf("failed to get passphrase confirmation: %w", err)
+	}
+
+	if repeated != passphrase {
+		return "", errors.New("passphrases do not match")
+	}
+
+	return passphrase, nil
+}
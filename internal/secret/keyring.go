@@ -0,0 +1,55 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+type keyringBackend struct {
+	service string
+}
+
+func newKeyringBackend() *keyringBackend {
+	return &keyringBackend{service: "minly"}
+}
+
+func (b *keyringBackend) Name() string {
+	return BackendKeyring
+}
+
+func (b *keyringBackend) Get(key Key) (string, error) {
+	value, err := keyring.Get(b.service, string(key))
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return "", ErrNotFound
+		}
+
+		return "", fmt.Errorf("failed to get key from keyring: %w", err)
+	}
+
+	return value, nil
+}
+
+func (b *keyringBackend) Set(key Key, value string) error {
+	err := keyring.Set(b.service, string(key), value)
+	if err != nil {
+		return fmt.Errorf("failed to set key in keyring: %w", err)
+	}
+
+	return nil
+}
+
+func (b *keyringBackend) Delete(key Key) error {
+	err := keyring.Delete(b.service, string(key))
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to delete key from keyring: %w", err)
+	}
+
+	return nil
+}
+
+func (b *keyringBackend) Writable() bool {
+	return true
+}
@@ -0,0 +1,118 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+func FromEnv(keys ...Key) (map[Key]string, error) {
+	if len(keys) == 0 {
+		keys = Keys()
+	}
+
+	values := make(map[Key]string)
+
+	var missing []string
+	for _, key := range keys {
+		value := os.Getenv(key.EnvName())
+		if value == "" {
+			missing = append(missing, key.EnvName())
+			continue
+		}
+
+		values[key] = value
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing environment variables: %s", strings.Join(missing, ", "))
+	}
+
+	return values, nil
+}
+
+func FromFile(path string) (map[Key]string, error) {
+	if path == "" {
+		return nil, errors.New("path cannot be empty")
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret file %s: %w", path, err)
+	}
+
+	var raw map[string]string
+	if filepath.Ext(path) == ".json" || bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		err = json.Unmarshal(b, &raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode JSON secret file %s: %w", path, err)
+		}
+	} else {
+		raw, err = godotenv.UnmarshalBytes(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode dotenv secret file %s: %w", path, err)
+		}
+	}
+
+	values := make(map[Key]string)
+
+	// Accept both the key names (minio_access_key) and
+	// the environment variable names (MINLY_MINIO_ACCESS_KEY).
+	for _, key := range AllKeys() {
+		value, ok := raw[string(key)]
+		if !ok {
+			value, ok = raw[key.EnvName()]
+		}
+
+		if ok && value != "" {
+			values[key] = value
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, fmt.Errorf("secret file %s does not contain any known secrets", path)
+	}
+
+	return values, nil
+}
+
+func FromReader(r io.Reader) (string, error) {
+	if r == nil {
+		return "", errors.New("reader cannot be nil")
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r, maxSecretLength+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+
+	if len(b) > maxSecretLength {
+		return "", fmt.Errorf("secret exceeds %d bytes", maxSecretLength)
+	}
+
+	value := strings.TrimRight(string(b), "\r\n")
+	if value == "" {
+		return "", errors.New("secret cannot be empty")
+	}
+
+	return value, nil
+}
+
+func ParseKey(s string) (Key, error) {
+	for _, key := range AllKeys() {
+		if s == string(key) || s == key.EnvName() {
+			return key, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown secret key %s", s)
+}
+
+const maxSecretLength = 64 * 1024
@@ -4,8 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
-	"github.com/zalando/go-keyring"
 	"golang.org/x/term"
 )
 
@@ -42,12 +43,50 @@ const (
 	MinioAccessKey    Key = "minio_access_key"
 	MinioAccessSecret Key = "minio_access_secret"
 	YOURLSignature    Key = "yourl_signature"
+
+	MinioTLSClientKey  Key = "minio_tls_client_key"
+	YOURLSTLSClientKey Key = "yourls_tls_client_key"
 )
 
+func Keys() []Key {
+	return []Key{MinioAccessKey, MinioAccessSecret, YOURLSignature}
+}
+
+func OptionalKeys() []Key {
+	return []Key{MinioTLSClientKey, YOURLSTLSClientKey}
+}
+
+func AllKeys() []Key {
+	return append(Keys(), OptionalKeys()...)
+}
+
+func (k Key) EnvName() string {
+	switch k {
+	case MinioAccessKey:
+		return "MINLY_MINIO_ACCESS_KEY"
+	case MinioAccessSecret:
+		return "MINLY_MINIO_ACCESS_SECRET"
+	case YOURLSignature:
+		return "MINLY_YOURLS_SIGNATURE"
+	default:
+		return "MINLY_" + strings.ToUpper(string(k))
+	}
+}
+
+func EnvOnly() bool {
+	s := strings.ToLower(os.Getenv("MINLY_SECRETS_ENV_ONLY"))
+	if s == "" {
+		return false
+	}
+
+	b, err := strconv.ParseBool(s)
+	return err == nil && b
+}
+
 func Exists(key Key) (bool, error) {
-	_, err := keyring.Get("minly", string(key))
+	_, err := Current().Get(key)
 	if err != nil {
-		if errors.Is(err, keyring.ErrNotFound) {
+		if errors.Is(err, ErrNotFound) {
 			return false, nil
 		}
 
@@ -58,10 +97,10 @@ func Exists(key Key) (bool, error) {
 }
 
 func Load(key Key) (string, error) {
-	value, err := keyring.Get("minly", string(key))
+	value, err := Current().Get(key)
 	if err != nil {
-		if errors.Is(err, keyring.ErrNotFound) {
-			return "", fmt.Errorf("key not found: %s", key)
+		if errors.Is(err, ErrNotFound) {
+			return "", fmt.Errorf("key not found: %s (%s backend): %w", key, Current().Name(), err)
 		}
 
 		return "", fmt.Errorf("failed to load key: %w", err)
@@ -75,7 +114,7 @@ func Save(key Key, value string) error {
 		return errors.New("value cannot be empty")
 	}
 
-	err := keyring.Set("minly", string(key), value)
+	err := Current().Set(key, value)
 	if err != nil {
 		return fmt.Errorf("failed to save key: %w", err)
 	}
@@ -83,10 +122,16 @@ func Save(key Key, value string) error {
 	return nil
 }
 
+func Writable() bool {
+	return Current().Writable()
+}
+
 func DeleteAll() error {
-	err := keyring.DeleteAll("minly")
-	if err != nil {
-		return fmt.Errorf("failed to delete all keys: %w", err)
+	for _, key := range AllKeys() {
+		err := Current().Delete(key)
+		if err != nil {
+			return fmt.Errorf("failed to delete key %s: %w", key, err)
+		}
 	}
 
 	return nil
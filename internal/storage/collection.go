@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const collectionsFile = "collections.json"
+
+type Collection struct {
+	ID               string           `json:"id"`
+	Name             string           `json:"name"`
+	Created          time.Time        `json:"created"`
+	Updated          time.Time        `json:"updated"`
+	Bucket           string           `json:"bucket"`
+	IndexKey         string           `json:"index_object_key"`
+	ManifestKey      string           `json:"manifest_object_key"`
+	IndexLink        string           `json:"index_link"`
+	IndexLinkExpires time.Time        `json:"index_link_expires"`
+	YOURLSLink       string           `json:"yourls_link"`
+	Permanent        bool             `json:"permanent,omitempty"`
+	Files            []CollectionFile `json:"files"`
+}
+
+type CollectionFile struct {
+	Name        string    `json:"name"`
+	ObjectKey   string    `json:"object_key"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Added       time.Time `json:"added"`
+}
+
+func NewCollection(name string, bucket string) *Collection {
+	now := time.Now()
+
+	return &Collection{
+		ID:               uuid.NewString(),
+		Name:             name,
+		Created:          now,
+		Updated:          now,
+		Bucket:           bucket,
+		IndexKey:         "",
+		ManifestKey:      "",
+		IndexLink:        "",
+		IndexLinkExpires: time.Time{},
+		YOURLSLink:       "",
+		Permanent:        false,
+		Files:            nil,
+	}
+}
+
+// Expired reports whether the index link of the collection has expired.
+func (c *Collection) Expired() bool {
+	return !c.Permanent && c.IndexLinkExpires.Before(time.Now())
+}
+
+// File returns the index of the file with the given name or object key, or -1.
+func (c *Collection) File(nameOrKey string) int {
+	return slices.IndexFunc(c.Files, func(f CollectionFile) bool {
+		return f.Name == nameOrKey || f.ObjectKey == nameOrKey
+	})
+}
+
+func (c *Collection) validate() error {
+	if c.ID == "" {
+		return errors.New("id is required")
+	}
+
+	err := ValidateCollectionName(c.Name)
+	if err != nil {
+		return err
+	}
+
+	if c.Bucket == "" {
+		return errors.New("bucket is required")
+	}
+
+	if c.IndexKey == "" || c.IndexLink == "" {
+		return errors.New("index_object_key and index_link are required")
+	}
+
+	if c.IndexLinkExpires.IsZero() && !c.Permanent {
+		return errors.New("index_link_expires is required")
+	}
+
+	if c.YOURLSLink == "" {
+		return errors.New("yourls_link is required")
+	}
+
+	return nil
+}
+
+func ValidateCollectionName(name string) error {
+	if name == "" {
+		return errors.New("collection name cannot be empty")
+	}
+
+	if strings.ContainsAny(name, "/\\\n\t") {
+		return fmt.Errorf("collection name %q cannot contain slashes or control characters", name)
+	}
+
+	return nil
+}
+
+// SaveCollection adds the collection or replaces the one with the same ID.
+func (fs *FileStore) SaveCollection(c *Collection) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if c == nil {
+		return errors.New("collection cannot be nil")
+	}
+
+	err := c.validate()
+	if err != nil {
+		return fmt.Errorf("collection validation failed: %w", err)
+	}
+
+	var collections []Collection
+	collections, err = fs.loadCollections()
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(collections, func(o Collection) bool { return o.ID == c.ID })
+	if i < 0 {
+		if slices.ContainsFunc(collections, func(o Collection) bool { return o.Name == c.Name }) {
+			return fmt.Errorf("collection %s already exists", c.Name)
+		}
+
+		collections = append(collections, *c)
+	} else {
+		collections[i] = *c
+	}
+
+	return fs.writeCollections(collections)
+}
+
+func (fs *FileStore) LoadCollections() ([]Collection, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return fs.loadCollections()
+}
+
+func (fs *FileStore) LoadCollection(name string) (*Collection, error) {
+	collections, err := fs.LoadCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range collections {
+		if c.Name == name {
+			return &c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("collection %s not found", name)
+}
+
+func (fs *FileStore) loadCollections() ([]Collection, error) {
+	filename := filepath.Join(fs.dir, collectionsFile)
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read file %s: %w", filename, err)
+	}
+
+	var collections []Collection
+	err = json.Unmarshal(b, &collections)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal file %s: %w", filename, err)
+	}
+
+	return collections, nil
+}
+
+func (fs *FileStore) writeCollections(collections []Collection) error {
+	filename := filepath.Join(fs.dir, collectionsFile)
+
+	b, err := json.MarshalIndent(collections, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal collections: %w", err)
+	}
+
+	tmpPath := filename + ".tmp"
+
+	err = os.WriteFile(tmpPath, b, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write temp file for %s: %w", filename, err)
+	}
+
+	err = os.Rename(tmpPath, filename)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace file %s: %w", filename, err)
+	}
+
+	return nil
+}
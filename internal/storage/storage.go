@@ -2,6 +2,7 @@ package storage
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,23 +12,80 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/devusSs/minly/internal/paths"
 )
 
 type File struct {
-	ID               string    `json:"id"`
-	Timestamp        time.Time `json:"timestamp"`
-	MinioLink        string    `json:"minio_link"`
-	MinioLinkExpires time.Time `json:"minio_link_expires"`
-	YOURLSLink       string    `json:"yourls_link"`
+	ID               string            `json:"id"`
+	Timestamp        time.Time         `json:"timestamp"`
+	MinioLink        string            `json:"minio_link"`
+	MinioLinkExpires time.Time         `json:"minio_link_expires"`
+	YOURLSLink       string            `json:"yourls_link"`
+	Permanent        bool              `json:"permanent,omitempty"`
+	Bucket           string            `json:"bucket,omitempty"`
+	ObjectKey        string            `json:"object_key,omitempty"`
+	Sanitized        bool              `json:"sanitized,omitempty"`
+	MetadataRemoved  []string          `json:"metadata_removed,omitempty"`
+	OriginalKey      string            `json:"original_object_key,omitempty"`
+	ArchiveMembers   int               `json:"archive_members,omitempty"`
+	ArchiveSize      int64             `json:"archive_size,omitempty"`
+	Language         string            `json:"language,omitempty"`
+	PreviewKey       string            `json:"preview_object_key,omitempty"`
+	SHA256           string            `json:"sha256,omitempty"`
+	Tags             map[string]string `json:"tags,omitempty"`
+	VersionID        string            `json:"version_id,omitempty"`
 }
 
-func NewFile(minioLink string, minioLinkExpires time.Time, yourlsLink string) *File {
+func NewFile(
+	bucket string,
+	objectKey string,
+	minioLink string,
+	minioLinkExpires time.Time,
+	yourlsLink string,
+) *File {
 	return &File{
 		ID:               uuid.NewString(),
 		Timestamp:        time.Now(),
 		MinioLink:        minioLink,
 		MinioLinkExpires: minioLinkExpires,
 		YOURLSLink:       yourlsLink,
+		Permanent:        false,
+		Bucket:           bucket,
+		ObjectKey:        objectKey,
+		Sanitized:        false,
+		MetadataRemoved:  nil,
+		OriginalKey:      "",
+		ArchiveMembers:   0,
+		ArchiveSize:      0,
+		Language:         "",
+		PreviewKey:       "",
+		SHA256:           "",
+		Tags:             nil,
+		VersionID:        "",
+	}
+}
+
+func NewPermanentFile(bucket string, objectKey string, minioLink string, yourlsLink string) *File {
+	return &File{
+		ID:               uuid.NewString(),
+		Timestamp:        time.Now(),
+		MinioLink:        minioLink,
+		MinioLinkExpires: time.Time{},
+		YOURLSLink:       yourlsLink,
+		Permanent:        true,
+		Bucket:           bucket,
+		ObjectKey:        objectKey,
+		Sanitized:        false,
+		MetadataRemoved:  nil,
+		OriginalKey:      "",
+		ArchiveMembers:   0,
+		ArchiveSize:      0,
+		Language:         "",
+		PreviewKey:       "",
+		SHA256:           "",
+		Tags:             nil,
+		VersionID:        "",
 	}
 }
 
@@ -48,7 +106,7 @@ func (f *File) validate() error {
 		return errors.New("minio_link is required")
 	}
 
-	if f.MinioLinkExpires.IsZero() {
+	if f.MinioLinkExpires.IsZero() && !f.Permanent {
 		return errors.New("minio_link_expires is required")
 	}
 
@@ -106,6 +164,70 @@ func (fs *FileStore) Save(file *File) error {
 	return nil
 }
 
+// Update replaces the stored record with the ID of file, e.g. after its
+// link changed. The timestamp cannot change, it selects the file to rewrite.
+func (fs *FileStore) Update(file *File) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if file == nil {
+		return errors.New("file cannot be nil")
+	}
+
+	err := file.validate()
+	if err != nil {
+		return fmt.Errorf("file validation failed: %w", err)
+	}
+
+	filename := filepath.Join(fs.dir, file.Timestamp.Format("2006-01")+".jsonl")
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", filename, err)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	found := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		var fobj File
+		err = json.Unmarshal(scanner.Bytes(), &fobj)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal file %s: %w", filename, err)
+		}
+
+		if fobj.ID == file.ID {
+			fobj = *file
+			found = true
+		}
+
+		err = enc.Encode(fobj)
+		if err != nil {
+			return fmt.Errorf("failed to encode file %s: %w", filename, err)
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("file %s not found in %s", file.ID, filename)
+	}
+
+	tmpPath := filename + ".tmp"
+
+	err = os.WriteFile(tmpPath, buf.Bytes(), 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write temp file for %s: %w", filename, err)
+	}
+
+	err = os.Rename(tmpPath, filename)
+	if err != nil {
+		return fmt.Errorf("failed to replace original file %s: %w", filename, err)
+	}
+
+	return nil
+}
+
 func (fs *FileStore) LoadAll() ([]File, error) {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
@@ -118,6 +240,10 @@ func (fs *FileStore) LoadAll() ([]File, error) {
 	var result []File
 
 	for _, entry := range files {
+		if entry.Name() == collectionsFile {
+			continue
+		}
+
 		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
 			return nil, fmt.Errorf("invalid file %s in storage directory", entry.Name())
 		}
@@ -196,7 +322,7 @@ func (fs *FileStore) CleanOldFiles() (int, error) {
 				return totalDeleted, fmt.Errorf("failed to unmarshal file %s: %w", fullPath, err)
 			}
 
-			if fobj.MinioLinkExpires.After(now) {
+			if fobj.Permanent || fobj.MinioLinkExpires.After(now) {
 				keep = append(keep, fobj)
 			} else {
 				totalDeleted++
@@ -247,16 +373,9 @@ func (fs *FileStore) CleanOldFiles() (int, error) {
 }
 
 func getStorageDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get user home directory: %w", err)
-	}
-
-	storageDir := filepath.Join(home, ".minly", "storage")
-
-	err = os.MkdirAll(storageDir, 0700)
+	storageDir, err := paths.StateDir("storage")
 	if err != nil {
-		return "", fmt.Errorf("failed to create storage directory %s: %w", storageDir, err)
+		return "", fmt.Errorf("failed to create storage directory: %w", err)
 	}
 
 	return storageDir, nil
@@ -0,0 +1,107 @@
+package transport
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/devusSs/minly/internal/version"
+)
+
+type Options struct {
+	ProxyURL              *url.URL
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	KeepAlive             time.Duration
+	TLS                   *tls.Config
+}
+
+func New(opts Options) http.RoundTripper {
+	dialer := &net.Dialer{
+		Timeout:   opts.DialTimeout,
+		KeepAlive: opts.KeepAlive,
+	}
+
+	t := &http.Transport{
+		Proxy:                 proxyFunc(opts.ProxyURL),
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdleConns,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       idleConnTimeout,
+		TLSHandshakeTimeout:   opts.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: opts.ResponseHeaderTimeout,
+		ExpectContinueTimeout: expectContinueTimeout,
+		TLSClientConfig:       opts.TLS,
+		// Objects stored with Content-Encoding gzip must not be decoded transparently.
+		DisableCompression: true,
+	}
+
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return &userAgentTransport{next: t, userAgent: UserAgent()}
+}
+
+func UserAgent() string {
+	b := version.GetBuild()
+	return fmt.Sprintf("minly/%s (%s/%s; commit %s; %s)", b.Version, b.GoOS, b.GoArch, b.Commit, b.GoVersion)
+}
+
+const (
+	maxIdleConns          = 64
+	maxIdleConnsPerHost   = 16
+	idleConnTimeout       = 90 * time.Second
+	expectContinueTimeout = 1 * time.Second
+)
+
+func proxyFunc(proxyURL *url.URL) func(*http.Request) (*url.URL, error) {
+	if proxyURL == nil {
+		return http.ProxyFromEnvironment
+	}
+
+	// An explicit proxy replaces HTTP(S)_PROXY but NO_PROXY is still honored.
+	noProxy := os.Getenv("NO_PROXY")
+	if noProxy == "" {
+		noProxy = os.Getenv("no_proxy")
+	}
+
+	cfg := &httpproxy.Config{
+		HTTPProxy:  proxyURL.String(),
+		HTTPSProxy: proxyURL.String(),
+		NoProxy:    noProxy,
+		CGI:        false,
+	}
+	fn := cfg.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return fn(req.URL)
+	}
+}
+
+type userAgentTransport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Libraries like minio-go set their own User-Agent, ours is appended to it.
+	// The header is not part of S3 signatures so changing it here is safe.
+	ua := req.Header.Get("User-Agent")
+	if !strings.Contains(ua, t.userAgent) {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", strings.TrimSpace(ua+" "+t.userAgent))
+	}
+
+	//nolint:wrapcheck // Round trippers must pass errors through unchanged.
+	return t.next.RoundTrip(req)
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+
+	"github.com/devusSs/minly/internal/paths"
 )
 
 func saveAssetToFile(ctx context.Context, a *asset) (string, error) {
@@ -18,7 +20,7 @@ func saveAssetToFile(ctx context.Context, a *asset) (string, error) {
 	}
 
 	var resp *http.Response
-	resp, err = http.DefaultClient.Do(req)
+	resp, err = httpClient().Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to download asset %s: %w", a.url, err)
 	}
@@ -60,14 +62,7 @@ func saveAssetToFile(ctx context.Context, a *asset) (string, error) {
 }
 
 func setupUpdatesDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get user home directory: %w", err)
-	}
-
-	updatesDir := filepath.Join(home, ".minly", "updates")
-
-	err = os.MkdirAll(updatesDir, 0700)
+	updatesDir, err := paths.CacheDir("updates")
 	if err != nil {
 		return "", fmt.Errorf("failed to create updates directory: %w", err)
 	}
@@ -98,7 +98,7 @@ func getLatestRelease(ctx context.Context) (*release, error) {
 	req.Header.Set("Accept", "application/json")
 
 	var resp *http.Response
-	resp, err = http.DefaultClient.Do(req)
+	resp, err = httpClient().Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
 	}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"time"
@@ -12,6 +13,10 @@ import (
 	"github.com/minio/selfupdate"
 )
 
+func SetTransport(t http.RoundTripper) {
+	transport = t
+}
+
 type Update struct {
 	Updated   bool      `json:"updated"`
 	Version   string    `json:"version"`
@@ -129,3 +134,13 @@ func DoUpdate(ctx context.Context, currentVersion string) (*Update, error) {
 		Changelog: release.Body,
 	}, nil
 }
+
+var transport http.RoundTripper //nolint:gochecknoglobals // SetTransport configures the package's subsequent requests.
+
+func httpClient() *http.Client {
+	if transport == nil {
+		return http.DefaultClient
+	}
+
+	return &http.Client{Transport: transport}
+}
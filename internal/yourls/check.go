@@ -0,0 +1,60 @@
+package yourls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func (c *Client) Check(ctx context.Context) error {
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+
+	v := url.Values{}
+	v.Set("signature", c.signature)
+	v.Set("action", checkAction)
+	v.Set("format", shortenFormat)
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		c.endpoint,
+		strings.NewReader(v.Encode()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp *http.Response
+	resp, err = c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var res checkResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return fmt.Errorf("failed to decode response (status %s): %w", resp.Status, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("check failed: %s (message: %s)", resp.Status, res.Message)
+	}
+
+	return nil
+}
+
+// db-stats is a cheap authenticated action without side effects.
+const checkAction = "db-stats"
+
+type checkResponse struct {
+	Message string `json:"message"`
+}
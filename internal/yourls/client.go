@@ -13,15 +13,20 @@ type Client struct {
 	title string
 }
 
-func NewClient(endpoint string, signature string) (*Client, error) {
+func NewClient(endpoint string, signature string, transport http.RoundTripper) (*Client, error) {
 	if signature == "" {
 		return nil, errors.New("signature is required")
 	}
 
+	client := http.DefaultClient
+	if transport != nil {
+		client = &http.Client{Transport: transport}
+	}
+
 	return &Client{
 		endpoint:  endpoint,
 		signature: signature,
-		client:    http.DefaultClient,
+		client:    client,
 
 		title: "Uploaded using minly (github.com/devusSs/minly)",
 	}, nil
@@ -0,0 +1,75 @@
+package yourls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Expand returns the long URL a short URL or keyword points to.
+func (c *Client) Expand(ctx context.Context, short string) (string, error) {
+	if ctx == nil {
+		return "", errors.New("context cannot be nil")
+	}
+
+	if short == "" {
+		return "", errors.New("short URL cannot be empty")
+	}
+
+	v := url.Values{}
+	v.Set("signature", c.signature)
+	v.Set("action", expandAction)
+	v.Set("shorturl", short)
+	v.Set("format", shortenFormat)
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		shortenHTTPMethod,
+		c.endpoint,
+		strings.NewReader(v.Encode()),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp *http.Response
+	resp, err = c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var res expandResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || res.Longurl == "" {
+		return "", fmt.Errorf(
+			"expand error: %s (message: %s, status: %d)",
+			res.Error,
+			res.Message,
+			resp.StatusCode,
+		)
+	}
+
+	return res.Longurl, nil
+}
+
+const expandAction = "expand"
+
+type expandResponse struct {
+	Keyword  string `json:"keyword"`
+	Shorturl string `json:"shorturl"`
+	Longurl  string `json:"longurl"`
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Error    string `json:"error"`
+}
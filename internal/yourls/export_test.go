@@ -0,0 +1,6 @@
+package yourls
+
+// Keyword extracts the keyword the update action expects.
+//
+//nolint:gochecknoglobals // Exported for tests only.
+var Keyword = keyword
@@ -0,0 +1,95 @@
+package yourls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrUpdateUnsupported is returned if the YOURLS instance does not know
+// the update action, which is added by the "API Edit URL" plugin.
+var ErrUpdateUnsupported = errors.New("updating short URLs needs the YOURLS API Edit URL plugin")
+
+// Update points an existing short URL or keyword to a new long URL.
+func (c *Client) Update(ctx context.Context, short string, long string) error {
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+
+	if short == "" || long == "" {
+		return errors.New("short and long URL cannot be empty")
+	}
+
+	v := url.Values{}
+	v.Set("signature", c.signature)
+	v.Set("action", updateAction)
+	v.Set("shorturl", keyword(short))
+	v.Set("url", long)
+	v.Set("title", c.title)
+	v.Set("format", shortenFormat)
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		shortenHTTPMethod,
+		c.endpoint,
+		strings.NewReader(v.Encode()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp *http.Response
+	resp, err = c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var res updateResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Without the plugin YOURLS rejects the action as unknown.
+	if strings.Contains(strings.ToLower(res.Message), "unknown or missing") {
+		return ErrUpdateUnsupported
+	}
+
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(res.Message, "success") {
+		return fmt.Errorf(
+			"update error: %s (status: %d)",
+			res.Message,
+			resp.StatusCode,
+		)
+	}
+
+	return nil
+}
+
+const updateAction = "update"
+
+type updateResponse struct {
+	Message    string `json:"message"`
+	StatusCode int    `json:"statusCode"`
+}
+
+// keyword returns the keyword of a short URL, the update action does not
+// accept full short URLs. Keywords cannot contain slashes, so YOURLS
+// installed in a sub directory only adds path segments before it.
+func keyword(short string) string {
+	u, err := url.Parse(short)
+	if err != nil || u.Host == "" {
+		return short
+	}
+
+	p := strings.Trim(u.Path, "/")
+
+	return p[strings.LastIndex(p, "/")+1:]
+}
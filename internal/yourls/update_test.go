@@ -0,0 +1,35 @@
+package yourls_test
+
+import (
+	"testing"
+
+	"github.com/devusSs/minly/internal/yourls"
+)
+
+func TestKeyword(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		short string
+		want  string
+	}{
+		{name: "short URL", short: "https://sho.rt/abc", want: "abc"},
+		{name: "trailing slash", short: "https://sho.rt/abc/", want: "abc"},
+		{name: "installed in a sub directory", short: "https://example.com/s/abc", want: "abc"},
+		{name: "no keyword", short: "https://sho.rt/", want: ""},
+		{name: "plain keyword", short: "abc", want: "abc"},
+		{name: "host without scheme", short: "sho.rt/abc", want: "sho.rt/abc"},
+		{name: "invalid URL", short: "%zz", want: "%zz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := yourls.Keyword(tt.short); got != tt.want {
+				t.Errorf("Keyword(%q) = %q, want %q", tt.short, got, tt.want)
+			}
+		})
+	}
+}